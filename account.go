@@ -2,16 +2,27 @@ package hivego
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"time"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v2"
 )
 
 type CustomTime time.Time
 
+const (
+	RoleOwner   = "owner"
+	RoleActive  = "active"
+	RolePosting = "posting"
+	RoleMemo    = "memo"
+)
+
 const customTimeLayout = "2006-01-02T15:04:05"
 
 type Authority struct {
 	AccountAuths    [][]interface{} `json:"account_auths"`
-	KeyAuths        [][]interface{} `json:"key_auths"`
+	KeyAuths        []KeyAuth       `json:"key_auths"`
 	WeightThreshold int             `json:"weight_threshold"`
 }
 
@@ -26,7 +37,7 @@ type AccountData struct {
 	Owner                         Authority     `json:"owner"`
 	Active                        Authority     `json:"active"`
 	Posting                       Authority     `json:"posting"`
-	MemoKey                       string        `json:"memo_key"`
+	MemoKey                       PublicKey     `json:"memo_key"`
 	JSONMetadata                  string        `json:"json_metadata"`
 	Proxy                         string        `json:"proxy"`
 	LastOwnerUpdate               CustomTime    `json:"last_owner_update"`
@@ -94,6 +105,30 @@ type AccountData struct {
 	PostingJSONMetadata           string        `json:"posting_json_metadata"`
 }
 
+// Authority returns the owner, active or posting authority of the account
+func (a AccountData) Authority(role string) (Authority, error) {
+	switch role {
+	case RoleOwner:
+		return a.Owner, nil
+	case RoleActive:
+		return a.Active, nil
+	case RolePosting:
+		return a.Posting, nil
+	}
+	return Authority{}, errors.New("unknown authority role: " + role)
+}
+
+// keyWeight sums the weights of the authority's keys that are present in keys
+func (a Authority) keyWeight(keys map[string]bool) int {
+	weight := 0
+	for _, keyAuth := range a.KeyAuths {
+		if keys[keyAuth.Key.String()] {
+			weight += int(keyAuth.Weight)
+		}
+	}
+	return weight
+}
+
 func (ct *CustomTime) UnmarshalJSON(b []byte) error {
 	str := string(b)
 	str = str[1 : len(str)-1]
@@ -115,8 +150,7 @@ func (h *HiveRpcNode) GetAccount(accountNames []string) ([]AccountData, error) {
 		method: "condenser_api.get_accounts",
 		params: params,
 	}
-	endpoint := h.address
-	res, err := h.rpcExec(endpoint, query)
+	res, err := h.rpcExec(query)
 	if err != nil {
 		return nil, err
 	}
@@ -128,3 +162,97 @@ func (h *HiveRpcNode) GetAccount(accountNames []string) ([]AccountData, error) {
 	}
 	return accountData, nil
 }
+
+// MatchPassword derives account's keys from password and returns the roles
+// whose derived key is one of the account's on-chain keys for that role
+func (h *HiveRpcNode) MatchPassword(account string, password string) ([]string, error) {
+	accounts, err := h.GetAccount([]string{account})
+	if err != nil {
+		return nil, err
+	}
+	if len(accounts) == 0 {
+		return nil, errors.New("account not found: " + account)
+	}
+
+	var matched []string
+	for _, role := range PasswordRoles {
+		pubKey := *h.network().PublicKeyString(KeyPairFromPassword(account, role, password).PublicKey)
+		if role == RoleMemo {
+			if accounts[0].MemoKey.String() == pubKey {
+				matched = append(matched, role)
+			}
+			continue
+		}
+
+		auth, err := accounts[0].Authority(role)
+		if err != nil {
+			return nil, err
+		}
+		if auth.keyWeight(map[string]bool{pubKey: true}) > 0 {
+			matched = append(matched, role)
+		}
+	}
+	return matched, nil
+}
+
+// GetKeyReferences returns, for each public key, the accounts whose owner,
+// active or posting authority contains it
+func (h *HiveRpcNode) GetKeyReferences(keys []string) ([][]string, error) {
+	query := hrpcQuery{
+		method: "account_by_key_api.get_key_references",
+		params: map[string]interface{}{"keys": keys},
+	}
+
+	var res struct {
+		Accounts [][]string `json:"accounts"`
+	}
+	err := h.rpcExecInto(query, &res)
+	if err != nil {
+		return nil, err
+	}
+	if len(res.Accounts) != len(keys) {
+		return nil, fmt.Errorf("expected references for %d keys, got %d", len(keys), len(res.Accounts))
+	}
+	return res.Accounts, nil
+}
+
+// KeyReference is a role of an account that a public key appears in. Weight
+// is the key's weight in the role's authority, or zero for the memo key.
+type KeyReference struct {
+	Account string
+	Role    string
+	Weight  int
+}
+
+// KeyReferences lists the accounts and roles that pubKey controls. Nodes do
+// not index memo keys, so the memo role is only reported for accounts found
+// through one of their other roles.
+func (h *HiveRpcNode) KeyReferences(pubKey *secp256k1.PublicKey) ([]KeyReference, error) {
+	keyStr := *h.network().PublicKeyString(pubKey)
+	refs, err := h.GetKeyReferences([]string{keyStr})
+	if err != nil {
+		return nil, err
+	}
+	if len(refs[0]) == 0 {
+		return nil, nil
+	}
+
+	accounts, err := h.GetAccount(refs[0])
+	if err != nil {
+		return nil, err
+	}
+
+	var keyRefs []KeyReference
+	for _, account := range accounts {
+		for _, role := range []string{RoleOwner, RoleActive, RolePosting} {
+			auth, _ := account.Authority(role)
+			if weight := auth.keyWeight(map[string]bool{keyStr: true}); weight > 0 {
+				keyRefs = append(keyRefs, KeyReference{account.Name, role, weight})
+			}
+		}
+		if account.MemoKey.String() == keyStr {
+			keyRefs = append(keyRefs, KeyReference{account.Name, RoleMemo, 0})
+		}
+	}
+	return keyRefs, nil
+}
@@ -0,0 +1,116 @@
+package hivego
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestMatchPassword(t *testing.T) {
+	node, h := newTestNode(t)
+	keys := KeysFromPassword("xeroc", "hunter2")
+	auth := func(role string) map[string]interface{} {
+		return map[string]interface{}{
+			"weight_threshold": 1,
+			"account_auths":    [][]interface{}{},
+			"key_auths":        [][]interface{}{{*keys[role].GetPublicKeyString(), 1}},
+		}
+	}
+	node.handle("condenser_api.get_accounts", func(json.RawMessage) (interface{}, error) {
+		return []map[string]interface{}{{
+			"name":     "xeroc",
+			"owner":    auth(RoleOwner),
+			"active":   auth(RoleActive),
+			"posting":  map[string]interface{}{"weight_threshold": 1, "key_auths": [][]interface{}{}},
+			"memo_key": *keys[RoleMemo].GetPublicKeyString(),
+		}}, nil
+	})
+
+	matched, err := h.MatchPassword("xeroc", "hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{RoleOwner, RoleActive, RoleMemo}
+	if !reflect.DeepEqual(matched, expected) {
+		t.Error("Expected", expected, "got", matched)
+	}
+
+	matched, err = h.MatchPassword("xeroc", "wrong")
+	if err != nil || len(matched) != 0 {
+		t.Error("Expected no matches, got", matched, err)
+	}
+}
+
+func TestGetAccountCustomNetwork(t *testing.T) {
+	custom := TestnetConfig
+	custom.AddressPrefix = "MYC"
+	node, h := newTestNode(t, WithNetwork(custom))
+	keys := KeysFromPassword("xeroc", "hunter2")
+	node.handle("condenser_api.get_accounts", func(json.RawMessage) (interface{}, error) {
+		return []map[string]interface{}{{
+			"name":     "xeroc",
+			"active":   map[string]interface{}{"weight_threshold": 1, "key_auths": [][]interface{}{{*custom.PublicKeyString(keys[RoleActive].PublicKey), 1}}},
+			"memo_key": *custom.PublicKeyString(keys[RoleMemo].PublicKey),
+		}}, nil
+	})
+
+	accounts, err := h.GetAccount([]string{"xeroc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prefix := accounts[0].MemoKey.Prefix(); prefix != "MYC" {
+		t.Error("Expected the memo key to keep prefix MYC, got", prefix)
+	}
+
+	matched, err := h.MatchPassword("xeroc", "hunter2")
+	expected := []string{RoleActive, RoleMemo}
+	if err != nil || !reflect.DeepEqual(matched, expected) {
+		t.Error("Expected", expected, "got", matched, err)
+	}
+}
+
+func TestKeyReferences(t *testing.T) {
+	node, h := newTestNode(t)
+	keyPair := KeyPairFromPassword("xeroc", RoleActive, "p")
+	keyStr := *keyPair.GetPublicKeyString()
+	node.handle("account_by_key_api.get_key_references", func(params json.RawMessage) (interface{}, error) {
+		var p struct {
+			Keys []string `json:"keys"`
+		}
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, err
+		}
+		if len(p.Keys) != 1 || p.Keys[0] != keyStr {
+			t.Error("Unexpected keys", p.Keys)
+		}
+		return map[string]interface{}{"accounts": [][]string{{"xeroc", "piston"}}}, nil
+	})
+	node.handle("condenser_api.get_accounts", func(json.RawMessage) (interface{}, error) {
+		return []map[string]interface{}{
+			{
+				"name":     "xeroc",
+				"active":   map[string]interface{}{"weight_threshold": 1, "key_auths": [][]interface{}{{keyStr, 1}}},
+				"posting":  map[string]interface{}{"weight_threshold": 1, "key_auths": [][]interface{}{{keyStr, 1}}},
+				"memo_key": keyStr,
+			},
+			{
+				"name":   "piston",
+				"active": map[string]interface{}{"weight_threshold": 2, "key_auths": [][]interface{}{{"STM6LLegbAgLAy28EHrffBVuANFWcFgmqRMW13wBmTExqFE9SCkg4", 1}, {keyStr, 1}}},
+			},
+		}, nil
+	})
+
+	refs, err := h.KeyReferences(keyPair.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []KeyReference{
+		{"xeroc", RoleActive, 1},
+		{"xeroc", RolePosting, 1},
+		{"xeroc", RoleMemo, 0},
+		{"piston", RoleActive, 1},
+	}
+	if !reflect.DeepEqual(refs, expected) {
+		t.Error("Expected", expected, "got", refs)
+	}
+}
@@ -0,0 +1,199 @@
+package hivego
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// maxSigCheckDepth is hived's HIVE_MAX_SIG_CHECK_DEPTH, how many levels of
+// account auths are followed when checking an authority
+const maxSigCheckDepth = 2
+
+// AuthorityLookup returns the authority of account for role, so authorities
+// can be checked against data fetched earlier or straight from a node
+type AuthorityLookup func(account string, role string) (Authority, error)
+
+// AccountsAuthorityLookup looks authorities up in accounts, without a node
+func AccountsAuthorityLookup(accounts []AccountData) AuthorityLookup {
+	byName := make(map[string]AccountData, len(accounts))
+	for _, account := range accounts {
+		byName[account.Name] = account
+	}
+	return func(account string, role string) (Authority, error) {
+		data, ok := byName[account]
+		if !ok {
+			return Authority{}, errors.New("account not found: " + account)
+		}
+		return data.Authority(role)
+	}
+}
+
+// AccountAuthorityLookup looks authorities up with GetAccount, fetching each
+// account once
+func (h *HiveRpcNode) AccountAuthorityLookup() AuthorityLookup {
+	var mu sync.Mutex
+	cache := map[string]AccountData{}
+	return func(account string, role string) (Authority, error) {
+		mu.Lock()
+		data, ok := cache[account]
+		mu.Unlock()
+		if !ok {
+			accounts, err := h.GetAccount([]string{account})
+			if err != nil {
+				return Authority{}, err
+			}
+			if len(accounts) == 0 {
+				return Authority{}, errors.New("account not found: " + account)
+			}
+			data = accounts[0]
+			mu.Lock()
+			cache[account] = data
+			mu.Unlock()
+		}
+		return data.Authority(role)
+	}
+}
+
+// CheckKeys reports whether keys satisfy the authority, which is the given
+// role (owner, active or posting) of its account, and returns a minimal
+// subset of keys that still does. Account auths are followed through lookup
+// up to hived's depth limit, using the accounts' posting authority when
+// checking a posting authority and their active authority otherwise.
+func (a Authority) CheckKeys(role string, keys []string, lookup AuthorityLookup) ([]string, bool, error) {
+	nestedRole := RoleActive
+	if role == RolePosting {
+		nestedRole = RolePosting
+	}
+
+	c := newAuthorityChecker(keys, nestedRole, lookup)
+	ok, err := c.check(a, 0)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+
+	var minimal []string
+	for _, key := range keys {
+		if c.used[key] {
+			minimal = append(minimal, key)
+		}
+	}
+
+	// drop keys the authority is still satisfied without
+	for i := 0; i < len(minimal); {
+		trial := append(append([]string{}, minimal[:i]...), minimal[i+1:]...)
+		ok, err := newAuthorityChecker(trial, nestedRole, lookup).check(a, 0)
+		if err != nil {
+			return nil, false, err
+		}
+		if ok {
+			minimal = trial
+		} else {
+			i++
+		}
+	}
+	return minimal, true, nil
+}
+
+// authorityChecker mirrors hived's sign_state
+type authorityChecker struct {
+	available  map[string]bool
+	used       map[string]bool
+	approved   map[string]bool
+	nestedRole string
+	lookup     AuthorityLookup
+}
+
+func newAuthorityChecker(keys []string, nestedRole string, lookup AuthorityLookup) *authorityChecker {
+	available := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		available[key] = true
+	}
+	return &authorityChecker{
+		available:  available,
+		used:       map[string]bool{},
+		approved:   map[string]bool{},
+		nestedRole: nestedRole,
+		lookup:     lookup,
+	}
+}
+
+func (c *authorityChecker) check(auth Authority, depth int) (bool, error) {
+	total := 0
+	for _, keyAuth := range auth.KeyAuths {
+		key := keyAuth.Key.String()
+		if c.available[key] {
+			c.used[key] = true
+			total += int(keyAuth.Weight)
+			if total >= auth.WeightThreshold {
+				return true, nil
+			}
+		}
+	}
+
+	for _, accountAuth := range auth.AccountAuths {
+		account, weight, err := authEntry(accountAuth)
+		if err != nil {
+			return false, err
+		}
+		if !c.approved[account] {
+			if depth == maxSigCheckDepth {
+				continue
+			}
+			nested, err := c.lookup(account, c.nestedRole)
+			if err != nil {
+				return false, err
+			}
+			ok, err := c.check(nested, depth+1)
+			if err != nil {
+				return false, err
+			}
+			if !ok {
+				continue
+			}
+			c.approved[account] = true
+		}
+		total += weight
+		if total >= auth.WeightThreshold {
+			return true, nil
+		}
+	}
+	return total >= auth.WeightThreshold, nil
+}
+
+// authEntry unpacks an [account, weight] pair of an authority
+func authEntry(entry []interface{}) (string, int, error) {
+	if len(entry) != 2 {
+		return "", 0, fmt.Errorf("invalid authority entry: %v", entry)
+	}
+	name, ok := entry[0].(string)
+	if !ok {
+		return "", 0, fmt.Errorf("invalid authority entry: %v", entry)
+	}
+	weight, err := authWeight(entry[1])
+	if err != nil {
+		return "", 0, err
+	}
+	return name, int(weight), nil
+}
+
+// GetRequiredSignatures asks the node which of availableKeys must sign tx
+func (h *HiveRpcNode) GetRequiredSignatures(tx *HiveTransaction, availableKeys []string) ([]string, error) {
+	tx.prepareJson()
+	txB, err := json.Marshal(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := hrpcQuery{
+		method: "condenser_api.get_required_signatures",
+		params: []interface{}{json.RawMessage(txB), availableKeys},
+	}
+	var keys []string
+	err = h.rpcExecInto(query, &keys)
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
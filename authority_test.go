@@ -0,0 +1,151 @@
+package hivego
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// testKey derives a distinct public key for name
+func testKey(name string) PublicKey {
+	return NewPublicKey(KeyPairFromPassword(name, RoleActive, "p").PublicKey)
+}
+
+func keyAuthority(threshold int, names ...string) Authority {
+	auth := Authority{WeightThreshold: threshold}
+	for _, name := range names {
+		auth.KeyAuths = append(auth.KeyAuths, KeyAuth{testKey(name), 1})
+	}
+	return auth
+}
+
+func testKeys(names ...string) []string {
+	var keys []string
+	for _, name := range names {
+		keys = append(keys, testKey(name).String())
+	}
+	return keys
+}
+
+func TestCheckKeysThreshold(t *testing.T) {
+	auth := keyAuthority(2, "1", "2", "3")
+
+	keys, ok, err := auth.CheckKeys(RoleActive, testKeys("3", "1", "2", "4"), nil)
+	if err != nil || !ok {
+		t.Fatal("Expected the authority to be satisfied, got", ok, err)
+	}
+	if len(keys) != 2 {
+		t.Error("Expected 2 keys, got", keys)
+	}
+
+	_, ok, err = auth.CheckKeys(RoleActive, testKeys("1", "4"), nil)
+	if err != nil || ok {
+		t.Error("Expected a single key not to meet the threshold, got", ok, err)
+	}
+}
+
+func TestCheckKeysAccountAuths(t *testing.T) {
+	accounts := []AccountData{
+		{Name: "bob", Active: keyAuthority(1, "bobActive"), Posting: keyAuthority(1, "bobPosting")},
+		{Name: "carol", Active: Authority{WeightThreshold: 1, AccountAuths: [][]interface{}{{"dave", float64(1)}}}},
+		{Name: "dave", Active: keyAuthority(1, "dave")},
+	}
+	lookup := AccountsAuthorityLookup(accounts)
+
+	auth := Authority{
+		WeightThreshold: 2,
+		KeyAuths:        []KeyAuth{{testKey("alice"), 1}},
+		AccountAuths:    [][]interface{}{{"bob", float64(1)}},
+	}
+	keys, ok, err := auth.CheckKeys(RoleActive, testKeys("bobPosting", "bobActive", "alice"), lookup)
+	if err != nil || !ok {
+		t.Fatal("Expected the authority to be satisfied, got", ok, err)
+	}
+	if expected := testKeys("bobActive", "alice"); !reflect.DeepEqual(keys, expected) {
+		t.Error("Expected", expected, "got", keys)
+	}
+
+	// a posting authority follows the posting authority of its account auths
+	posting := Authority{WeightThreshold: 1, AccountAuths: [][]interface{}{{"bob", float64(1)}}}
+	if _, ok, _ := posting.CheckKeys(RolePosting, testKeys("bobActive"), lookup); ok {
+		t.Error("Expected bob's active key not to satisfy a posting authority")
+	}
+	if keys, ok, _ := posting.CheckKeys(RolePosting, testKeys("bobPosting"), lookup); !ok || len(keys) != 1 {
+		t.Error("Expected bob's posting key to satisfy the posting authority, got", keys, ok)
+	}
+
+	// alice -> carol -> dave is within the depth limit, one more level is not
+	direct := Authority{WeightThreshold: 1, AccountAuths: [][]interface{}{{"carol", float64(1)}}}
+	if _, ok, err := direct.CheckKeys(RoleActive, testKeys("dave"), lookup); err != nil || !ok {
+		t.Error("Expected dave's key to satisfy the authority through carol, got", ok, err)
+	}
+	accounts = append(accounts, AccountData{Name: "erin", Active: direct})
+	deep := Authority{WeightThreshold: 1, AccountAuths: [][]interface{}{{"erin", float64(1)}}}
+	if _, ok, err := deep.CheckKeys(RoleActive, testKeys("dave"), AccountsAuthorityLookup(accounts)); err != nil || ok {
+		t.Error("Expected account auths past the depth limit to be ignored, got", ok, err)
+	}
+
+	missing := Authority{WeightThreshold: 1, AccountAuths: [][]interface{}{{"nobody", float64(1)}}}
+	if _, _, err := missing.CheckKeys(RoleActive, nil, lookup); err == nil {
+		t.Error("Expected an error for an unknown account")
+	}
+}
+
+func TestVerifyAuthorityAccountAuths(t *testing.T) {
+	node, h := newTestNode(t)
+	wif := "5JuMt237G3m3BaT7zH4YdoycUtbw4AEPy6DLdCrKAnFGAtXyQ1W"
+	keyPair, _ := KeyPairFromWif(wif)
+	node.handle("condenser_api.get_accounts", func(params json.RawMessage) (interface{}, error) {
+		var names [][]string
+		if err := json.Unmarshal(params, &names); err != nil {
+			return nil, err
+		}
+		switch names[0][0] {
+		case "xeroc":
+			return []map[string]interface{}{{
+				"name":    "xeroc",
+				"posting": map[string]interface{}{"weight_threshold": 1, "account_auths": [][]interface{}{{"app", 1}}},
+			}}, nil
+		case "app":
+			return []map[string]interface{}{{
+				"name":    "app",
+				"posting": map[string]interface{}{"weight_threshold": 1, "key_auths": [][]interface{}{{*keyPair.GetPublicKeyString(), 1}}},
+			}}, nil
+		}
+		return []map[string]interface{}{}, nil
+	})
+
+	tx := getTestVoteTx()
+	digest, _ := tx.Digest()
+	sig, _ := SignDigest(digest, &wif)
+	tx.Signatures = []string{hex.EncodeToString(sig)}
+
+	if err := h.VerifyAuthority(&tx, "xeroc", RolePosting); err != nil {
+		t.Error("Expected the app's signature to satisfy xeroc's posting authority, got", err)
+	}
+}
+
+func TestGetRequiredSignatures(t *testing.T) {
+	node, h := newTestNode(t)
+	node.handle("condenser_api.get_required_signatures", func(params json.RawMessage) (interface{}, error) {
+		var p []json.RawMessage
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, err
+		}
+		var keys []string
+		if err := json.Unmarshal(p[1], &keys); err != nil {
+			return nil, err
+		}
+		return keys[:1], nil
+	})
+
+	tx := getTestVoteTx()
+	keys, err := h.GetRequiredSignatures(&tx, []string{"STM1", "STM2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(keys, []string{"STM1"}) {
+		t.Error("Expected [STM1], got", keys)
+	}
+}
@@ -0,0 +1,259 @@
+package hivego
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	// maxTransactionSize is hived's HIVE_MAX_TRANSACTION_SIZE
+	maxTransactionSize = 64 * 1024
+
+	// txOverheadSize covers everything in a signed transaction besides its
+	// operations: TaPoS, expiration, counts, extensions and one signature
+	txOverheadSize = 2 + 4 + 4 + 5 + 1 + 1 + 65
+
+	// defaultBatchMaxOps is hived's limit on how many custom operations a
+	// single account may include in one block
+	defaultBatchMaxOps   = 5
+	defaultBatchInterval = 3 * time.Second
+)
+
+var ErrBatcherClosed = errors.New("batcher is closed")
+
+// BatchResult is delivered to each caller once the transaction carrying its
+// operation has been broadcast
+type BatchResult struct {
+	TxId string
+	Err  error
+}
+
+type batchRequest struct {
+	op         HiveOperation
+	serialized string
+	// accounts whose per block operation limit op counts against
+	accounts []string
+	result   chan BatchResult
+}
+
+// Batcher packs operations submitted from any number of goroutines into as few
+// transactions as possible, broadcast with the same semantics as
+// HiveRpcNode.Broadcast. It broadcasts at most one transaction per flush
+// interval, holding at most maxOps operations for any one account, since
+// hived limits how many custom operations an account may include in a block.
+// A transaction is broadcast as soon as it is full if the previous one went
+// out at least an interval ago, and otherwise once the interval elapses;
+// operations that do not fit wait for the next one. An operation identical to
+// one already in the transaction also waits, since transaction validation
+// rejects duplicate operations.
+type Batcher struct {
+	h        *HiveRpcNode
+	signer   Signer
+	maxOps   int
+	interval time.Duration
+
+	mu       sync.RWMutex
+	closed   bool
+	sending  sync.WaitGroup
+	requests chan batchRequest
+	done     chan struct{}
+}
+
+// NewBatcher starts a Batcher signing with signer. A maxOps or interval of zero
+// selects the defaults of 5 operations per account and 3 seconds (one block).
+func (h *HiveRpcNode) NewBatcher(signer Signer, maxOps int, interval time.Duration) *Batcher {
+	if maxOps <= 0 {
+		maxOps = defaultBatchMaxOps
+	}
+	if interval <= 0 {
+		interval = defaultBatchInterval
+	}
+
+	b := &Batcher{
+		h:        h,
+		signer:   signer,
+		maxOps:   maxOps,
+		interval: interval,
+		requests: make(chan batchRequest, maxOps),
+		done:     make(chan struct{}),
+	}
+	go b.run()
+	return b
+}
+
+// Submit queues op for the next batch. The returned channel receives exactly
+// one result once the batch is broadcast.
+func (b *Batcher) Submit(op HiveOperation) <-chan BatchResult {
+	result := make(chan BatchResult, 1)
+
+	if err := op.Validate(); err != nil {
+		result <- BatchResult{Err: err}
+		return result
+	}
+
+	opB, err := op.SerializeOp()
+	if err != nil {
+		result <- BatchResult{Err: err}
+		return result
+	}
+	if len(opB)+txOverheadSize > maxTransactionSize {
+		result <- BatchResult{Err: errors.New("operation exceeds the maximum transaction size")}
+		return result
+	}
+
+	b.mu.RLock()
+	if b.closed {
+		b.mu.RUnlock()
+		result <- BatchResult{Err: ErrBatcherClosed}
+		return result
+	}
+	b.sending.Add(1)
+	b.mu.RUnlock()
+
+	// sent outside the lock so Close is not held up by a busy batcher
+	defer b.sending.Done()
+	b.requests <- batchRequest{op: op, serialized: string(opB), accounts: opAccounts(op), result: result}
+	return result
+}
+
+// Close broadcasts any pending operations, still at most one transaction per
+// interval, and stops the batcher. Submit fails with ErrBatcherClosed
+// afterwards.
+func (b *Batcher) Close() {
+	b.mu.Lock()
+	closing := !b.closed
+	b.closed = true
+	b.mu.Unlock()
+
+	if closing {
+		b.sending.Wait()
+		close(b.requests)
+	}
+	<-b.done
+}
+
+func (b *Batcher) run() {
+	defer close(b.done)
+
+	var queue []batchRequest
+	var lastFlush time.Time
+	flush := func() {
+		var batch []batchRequest
+		batch, queue, _ = b.nextBatch(queue)
+		b.broadcast(batch)
+		lastFlush = time.Now()
+	}
+
+	timer := time.NewTimer(b.interval)
+	timer.Stop()
+	armed := false
+	for {
+		select {
+		case req, ok := <-b.requests:
+			if !ok {
+				if armed {
+					timer.Stop()
+				}
+				for len(queue) > 0 {
+					time.Sleep(time.Until(lastFlush.Add(b.interval)))
+					flush()
+				}
+				return
+			}
+			queue = append(queue, req)
+
+			if _, _, full := b.nextBatch(queue); full && time.Since(lastFlush) >= b.interval {
+				if armed && !timer.Stop() {
+					<-timer.C
+				}
+				armed = false
+				flush()
+			}
+		case <-timer.C:
+			armed = false
+			flush()
+		}
+
+		if !armed && len(queue) > 0 {
+			timer.Reset(b.interval)
+			armed = true
+		}
+	}
+}
+
+// nextBatch picks the operations for the next transaction from the front of
+// queue, keeping each account's operations in order, and reports whether the
+// transaction is full, so that waiting could not add more to it
+func (b *Batcher) nextBatch(queue []batchRequest) (batch []batchRequest, rest []batchRequest, full bool) {
+	size := txOverheadSize
+	perAccount := make(map[string]int)
+	blocked := make(map[string]bool)
+	inBatch := make(map[string]bool)
+	for i, req := range queue {
+		if size+len(req.serialized) > maxTransactionSize {
+			return batch, append(rest, queue[i:]...), true
+		}
+
+		take := !inBatch[req.serialized]
+		for _, account := range req.accounts {
+			if blocked[account] || perAccount[account] >= b.maxOps {
+				take = false
+			}
+		}
+		if !take {
+			// later operations of the same accounts must not overtake it
+			for _, account := range req.accounts {
+				blocked[account] = true
+			}
+			rest = append(rest, req)
+			full = true
+			continue
+		}
+
+		batch = append(batch, req)
+		size += len(req.serialized)
+		inBatch[req.serialized] = true
+		for _, account := range req.accounts {
+			perAccount[account]++
+		}
+	}
+
+	for _, n := range perAccount {
+		if n >= b.maxOps {
+			full = true
+		}
+	}
+	return batch, rest, full
+}
+
+// opAccounts lists the accounts authorizing op. Operations whose authorities
+// are not known share a single limit.
+func opAccounts(op HiveOperation) []string {
+	auths, err := requiredAuthorities(op)
+	if err != nil || len(auths) == 0 {
+		return []string{""}
+	}
+
+	var accounts []string
+	seen := make(map[string]bool)
+	for _, auth := range auths {
+		if !seen[auth.account] {
+			seen[auth.account] = true
+			accounts = append(accounts, auth.account)
+		}
+	}
+	return accounts
+}
+
+func (b *Batcher) broadcast(batch []batchRequest) {
+	ops := make([]HiveOperation, 0, len(batch))
+	for _, req := range batch {
+		ops = append(ops, req.op)
+	}
+
+	txId, err := b.h.Broadcast(ops, b.signer)
+	for _, req := range batch {
+		req.result <- BatchResult{TxId: txId, Err: err}
+	}
+}
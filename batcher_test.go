@@ -0,0 +1,149 @@
+package hivego
+
+import (
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBatcherFlushesFullBatches(t *testing.T) {
+	node, h := newTestNode(t)
+	signer, _ := NewKeySigner("5JuMt237G3m3BaT7zH4YdoycUtbw4AEPy6DLdCrKAnFGAtXyQ1W")
+	b := h.NewBatcher(signer, 3, 50*time.Millisecond)
+
+	var wg sync.WaitGroup
+	results := make([]BatchResult, 6)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = <-b.Submit(NewCustomJsonOp([]string{}, []string{"xeroc"}, "test-id", `{"n":`+strconv.Itoa(i)+`}`))
+		}(i)
+	}
+	wg.Wait()
+	b.Close()
+
+	for _, res := range results {
+		if res.Err != nil || len(res.TxId) != 40 {
+			t.Error("Expected a txid, got", res)
+		}
+	}
+
+	batches := node.broadcastOps()
+	if len(batches) != 2 || len(batches[0]) != 3 || len(batches[1]) != 3 {
+		t.Error("Expected 2 transactions of 3 operations, got", batches)
+	}
+}
+
+func TestBatcherFlushesOnIntervalAndClose(t *testing.T) {
+	node, h := newTestNode(t)
+	signer, _ := NewKeySigner("5JuMt237G3m3BaT7zH4YdoycUtbw4AEPy6DLdCrKAnFGAtXyQ1W")
+	b := h.NewBatcher(signer, 5, 50*time.Millisecond)
+
+	if res := <-b.Submit(getTestCustomJsonOp()); res.Err != nil {
+		t.Fatal(res.Err)
+	}
+
+	pending := b.Submit(getTestCustomJsonOp())
+	b.Close()
+	if res := <-pending; res.Err != nil {
+		t.Fatal(res.Err)
+	}
+
+	if res := <-b.Submit(getTestCustomJsonOp()); res.Err != ErrBatcherClosed {
+		t.Error("Expected", ErrBatcherClosed, "got", res.Err)
+	}
+
+	if got := len(node.broadcastOps()); got != 2 {
+		t.Error("Expected", 2, "transactions, got", got)
+	}
+}
+
+func TestBatcherSplitsDuplicateOps(t *testing.T) {
+	node, h := newTestNode(t)
+	signer, _ := NewKeySigner("5JuMt237G3m3BaT7zH4YdoycUtbw4AEPy6DLdCrKAnFGAtXyQ1W")
+	b := h.NewBatcher(signer, 5, 50*time.Millisecond)
+
+	first := b.Submit(getTestCustomJsonOp())
+	second := b.Submit(getTestCustomJsonOp())
+	b.Close()
+
+	for _, res := range []BatchResult{<-first, <-second} {
+		if res.Err != nil {
+			t.Error("Expected a txid, got", res)
+		}
+	}
+	if got := len(node.broadcastOps()); got != 2 {
+		t.Error("Expected", 2, "transactions, got", got)
+	}
+}
+
+func TestBatcherOneTransactionPerInterval(t *testing.T) {
+	node, h := newTestNode(t)
+	signer, _ := NewKeySigner("5JuMt237G3m3BaT7zH4YdoycUtbw4AEPy6DLdCrKAnFGAtXyQ1W")
+	interval := 100 * time.Millisecond
+	b := h.NewBatcher(signer, 0, interval)
+
+	// 3 ops from one account, then a burst of 12 from another
+	var results []<-chan BatchResult
+	for i := 0; i < 3; i++ {
+		results = append(results, b.Submit(NewCustomJsonOp([]string{}, []string{"piston"}, "test-id", `{"n":`+strconv.Itoa(i)+`}`)))
+	}
+	for i := 0; i < 12; i++ {
+		results = append(results, b.Submit(NewCustomJsonOp([]string{}, []string{"xeroc"}, "test-id", `{"n":`+strconv.Itoa(i)+`}`)))
+	}
+	for _, result := range results {
+		if res := <-result; res.Err != nil {
+			t.Fatal(res.Err)
+		}
+	}
+	b.Close()
+
+	perTx := func(ops []interface{}) map[string]int {
+		counts := map[string]int{}
+		for _, op := range ops {
+			auths := op.([]interface{})[1].(map[string]interface{})["required_posting_auths"].([]interface{})
+			counts[auths[0].(string)]++
+		}
+		return counts
+	}
+	batches := node.broadcastOps()
+	if len(batches) != 3 {
+		t.Fatal("Expected 3 transactions, got", len(batches))
+	}
+	for i, expected := range []map[string]int{{"xeroc": 5, "piston": 3}, {"xeroc": 5}, {"xeroc": 2}} {
+		if got := perTx(batches[i]); !reflect.DeepEqual(got, expected) {
+			t.Error("Expected", expected, "in transaction", i, "got", got)
+		}
+	}
+
+	times := node.broadcastTimestamps()
+	for i := 1; i < len(times); i++ {
+		if gap := times[i].Sub(times[i-1]); gap < interval {
+			t.Error("Expected broadcasts at least", interval, "apart, got", gap)
+		}
+	}
+}
+
+// run with -race to check Close does not wait behind blocked submitters
+func TestBatcherCloseWithConcurrentSubmits(t *testing.T) {
+	_, h := newTestNode(t)
+	signer, _ := NewKeySigner("5JuMt237G3m3BaT7zH4YdoycUtbw4AEPy6DLdCrKAnFGAtXyQ1W")
+	b := h.NewBatcher(signer, 2, 10*time.Millisecond)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			res := <-b.Submit(NewCustomJsonOp([]string{}, []string{"xeroc"}, "test-id", `{"n":`+strconv.Itoa(i)+`}`))
+			if res.Err != nil && res.Err != ErrBatcherClosed {
+				t.Error(res.Err)
+			}
+		}(i)
+	}
+	b.Close()
+	wg.Wait()
+}
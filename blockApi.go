@@ -157,13 +157,6 @@ var OperationType = operationTypes{
 }
 
 func (h *HiveRpcNode) GetBlockRange(startBlock int, count int) (<-chan Block, error) {
-	if h.MaxConn < 10 {
-		h.MaxConn = 10
-	}
-	if h.MaxBatch < 4 {
-		h.MaxBatch = 4
-	}
-
 	blockChan := make(chan Block)
 	go func() {
 		defer close(blockChan)
@@ -199,7 +192,7 @@ func (h *HiveRpcNode) StreamBlocks() (<-chan Block, error) {
 
 	go func() {
 		dynProps := hrpcQuery{method: "condenser_api.get_dynamic_global_properties", params: []string{}}
-		res, err := h.rpcExec(h.address, dynProps)
+		res, err := h.rpcExec(dynProps)
 		if err != nil {
 			log.Fatalf("Failed to fetch dynamic global properties: %v", err)
 			close(blockChan)
@@ -238,30 +231,19 @@ func (h *HiveRpcNode) fetchBlockInRange(startBlock, count int) ([]Block, error)
 	query := hrpcQuery{method: "block_api.get_block_range", params: params}
 	queries := []hrpcQuery{query}
 
-	endpoint := h.address
-	res, err := h.rpcExecBatchFast(endpoint, queries)
+	res, err := h.rpcExecBlockBatch(queries)
 	if err != nil {
 		return nil, err
 	}
 
-	var blockRangeResponses []struct {
-		ID      int    `json:"id"`
-		JsonRPC string `json:"jsonrpc"`
-		Result  struct {
-			Blocks []Block `json:"blocks"`
-		} `json:"result"`
+	var blockRange struct {
+		Blocks []Block `json:"blocks"`
 	}
-
-	err = json.Unmarshal(res[0], &blockRangeResponses)
+	err = json.Unmarshal(res[0], &blockRange)
 	if err != nil {
 		return nil, err
 	}
-
-	var blocks []Block
-	for _, blockRangeResponse := range blockRangeResponses {
-		blocks = append(blocks, blockRangeResponse.Result.Blocks...)
-	}
-	return blocks, nil
+	return blockRange.Blocks, nil
 }
 
 func (h *HiveRpcNode) fetchBlock(params []getBlockQueryParams) ([]Block, error) {
@@ -271,28 +253,21 @@ func (h *HiveRpcNode) fetchBlock(params []getBlockQueryParams) ([]Block, error)
 		queries = append(queries, query)
 	}
 
-	endpoint := h.address
-	res, err := h.rpcExecBatchFast(endpoint, queries)
-	if err != nil {
-		return nil, err
-	}
-
-	var blockResponses []struct {
-		ID      int    `json:"id"`
-		JsonRPC string `json:"jsonrpc"`
-		Result  struct {
-			Block Block `json:"block"`
-		} `json:"result"`
-	}
-
-	err = json.Unmarshal(res[0], &blockResponses)
+	res, err := h.rpcExecBlockBatch(queries)
 	if err != nil {
 		return nil, err
 	}
 
 	var blocks []Block
-	for _, blockResponse := range blockResponses {
-		blocks = append(blocks, blockResponse.Result.Block)
+	for _, result := range res {
+		var blockResponse struct {
+			Block Block `json:"block"`
+		}
+		err = json.Unmarshal(result, &blockResponse)
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, blockResponse.Block)
 	}
 	return blocks, nil
 }
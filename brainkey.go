@@ -0,0 +1,83 @@
+package hivego
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v2"
+)
+
+// BrainKeyWordCount is the number of words in a suggested brain key
+const BrainKeyWordCount = 16
+
+// BrainKeyInfo mirrors the result of cli_wallet's suggest_brain_key
+type BrainKeyInfo struct {
+	BrainPrivKey string `json:"brain_priv_key"`
+	WifPrivKey   string `json:"wif_priv_key"`
+	PubKey       string `json:"pub_key"`
+}
+
+// SuggestBrainKey picks BrainKeyWordCount words from words with 512 bits of
+// crypto/rand entropy, the way cli_wallet's suggest_brain_key does, and
+// derives the brain key's first key. Pass cli_wallet's dictionary as words
+// for brain keys it would also suggest.
+func SuggestBrainKey(words []string) (*BrainKeyInfo, error) {
+	if len(words) < 2 {
+		return nil, errors.New("brain key word list needs at least two words")
+	}
+
+	entropyB := make([]byte, 64)
+	if _, err := rand.Read(entropyB); err != nil {
+		return nil, err
+	}
+	entropy := new(big.Int).SetBytes(entropyB)
+	listSize := big.NewInt(int64(len(words)))
+
+	chosen := make([]string, BrainKeyWordCount)
+	choice := new(big.Int)
+	for i := range chosen {
+		entropy.DivMod(entropy, listSize, choice)
+		chosen[i] = words[choice.Int64()]
+	}
+
+	brainKey := NormalizeBrainKey(strings.Join(chosen, " "))
+	keyPair := KeyPairFromBrainKey(brainKey, 0)
+	return &BrainKeyInfo{
+		BrainPrivKey: brainKey,
+		WifPrivKey:   keyPair.Wif(),
+		PubKey:       *keyPair.GetPublicKeyString(),
+	}, nil
+}
+
+// NormalizeBrainKey upper cases the ASCII letters of a brain key and
+// collapses its whitespace to single spaces, as cli_wallet does before
+// deriving keys from it
+func NormalizeBrainKey(brainKey string) string {
+	return strings.Map(upperASCII, strings.Join(strings.FieldsFunc(brainKey, isBrainKeySpace), " "))
+}
+
+func isBrainKeySpace(r rune) bool {
+	return r < unicode.MaxASCII && unicode.IsSpace(r)
+}
+
+func upperASCII(r rune) rune {
+	if r >= 'a' && r <= 'z' {
+		return r - 'a' + 'A'
+	}
+	return r
+}
+
+// KeyPairFromBrainKey derives the key at sequence from a brain key,
+// sha256(sha512(brainKey + " " + sequence)) after normalizing the brain key
+func KeyPairFromBrainKey(brainKey string, sequence int) *KeyPair {
+	h := sha512.Sum512([]byte(NormalizeBrainKey(brainKey) + " " + strconv.Itoa(sequence)))
+	seed := sha256.Sum256(h[:])
+	prvKey, pubKey := secp256k1.PrivKeyFromBytes(seed[:])
+	return &KeyPair{prvKey, pubKey}
+}
@@ -0,0 +1,62 @@
+package hivego
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeBrainKey(t *testing.T) {
+	// only ASCII letters are upper cased
+	got := NormalizeBrainKey("  thrash\tSudden \r\n ammonia\vé  ")
+	expected := "THRASH SUDDEN AMMONIA é"
+	if got != expected {
+		t.Error("Expected", expected, "got", got)
+	}
+}
+
+func TestKeyPairFromBrainKey(t *testing.T) {
+	h := sha512.Sum512([]byte("THRASH SUDDEN AMMONIA 3"))
+	seed := sha256.Sum256(h[:])
+
+	keyPair := KeyPairFromBrainKey("thrash  sudden ammonia", 3)
+	if !bytes.Equal(keyPair.PrivateKey.Serialize(), seed[:]) {
+		t.Error("Expected the key to be sha256(sha512(brain key + \" \" + sequence))")
+	}
+	if KeyPairFromBrainKey("THRASH SUDDEN AMMONIA", 4).PublicKey.IsEqual(keyPair.PublicKey) {
+		t.Error("Expected each sequence to get its own key")
+	}
+}
+
+func TestSuggestBrainKey(t *testing.T) {
+	words := []string{"abaca", "abased", "abater", "abbacy", "zymogen"}
+	info, err := SuggestBrainKey(words)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chosen := strings.Split(info.BrainPrivKey, " ")
+	if len(chosen) != BrainKeyWordCount {
+		t.Fatal("Expected", BrainKeyWordCount, "words, got", info.BrainPrivKey)
+	}
+	for _, word := range chosen {
+		found := false
+		for _, w := range words {
+			found = found || strings.ToUpper(w) == word
+		}
+		if !found {
+			t.Error("Unexpected word", word)
+		}
+	}
+
+	keyPair := KeyPairFromBrainKey(info.BrainPrivKey, 0)
+	if info.WifPrivKey != keyPair.Wif() || info.PubKey != *keyPair.GetPublicKeyString() {
+		t.Error("Expected the keys of sequence 0")
+	}
+
+	if _, err = SuggestBrainKey(nil); err == nil {
+		t.Error("Expected an empty word list to be rejected")
+	}
+}
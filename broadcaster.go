@@ -2,9 +2,23 @@ package hivego
 
 import (
 	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
 )
 
-type hiveTransaction struct {
+const (
+	confirmPollInterval  = 1000 * time.Millisecond
+	defaultRetryInterval = 3 * time.Second
+)
+
+var (
+	ErrConfirmTimeout     = errors.New("timed out waiting for transaction confirmation")
+	ErrTransactionExpired = errors.New("transaction expired without being included in a block")
+)
+
+type HiveTransaction struct {
 	RefBlockNum    uint16           `json:"ref_block_num"`
 	RefBlockPrefix uint32           `json:"ref_block_prefix"`
 	Expiration     string           `json:"expiration"`
@@ -12,9 +26,12 @@ type hiveTransaction struct {
 	OperationsJs   [][2]interface{} `json:"operations"`
 	Extensions     []string         `json:"extensions"`
 	Signatures     []string         `json:"signatures"`
+
+	// network the transaction is signed for, nil for mainnet
+	network *NetworkConfig
 }
 
-func (t *hiveTransaction) generateTrxId() (string, error) {
+func (t *HiveTransaction) generateTrxId() (string, error) {
 	tB, err := serializeTx(*t)
 	if err != nil {
 		return "", err
@@ -24,7 +41,7 @@ func (t *hiveTransaction) generateTrxId() (string, error) {
 	return hex.EncodeToString(digest)[0:40], nil
 }
 
-func (t *hiveTransaction) prepareJson() {
+func (t *HiveTransaction) prepareJson() {
 	var opsContainer [][2]interface{}
 	for _, op := range t.Operations {
 		var opContainer [2]interface{}
@@ -38,44 +55,291 @@ func (t *hiveTransaction) prepareJson() {
 	t.OperationsJs = opsContainer
 }
 
-func (h *HiveRpcNode) Broadcast(ops []HiveOperation, wif *string) (string, error) {
-	signingData, err := h.getSigningData()
+func (h *HiveRpcNode) Broadcast(ops []HiveOperation, signer Signer) (string, error) {
+	tx, txId, err := h.signTx(ops, signer)
 	if err != nil {
 		return "", err
 	}
-	tx := hiveTransaction{
+
+	if !h.noBroadcast {
+		res, err := h.broadcastTx(tx)
+		if err != nil {
+			return string(res), err
+		}
+	}
+
+	return txId, nil
+}
+
+// BroadcastConfirmation describes where a broadcast transaction was included
+type BroadcastConfirmation struct {
+	TxId         string
+	BlockNum     int
+	TrxNum       int
+	Irreversible bool
+}
+
+// BroadcastSync broadcasts ops and waits until the transaction is included in
+// a block, or in an irreversible block when waitIrreversible is set. It fails
+// with ErrConfirmTimeout once timeout elapses, returning whatever was confirmed
+// so far, and with ErrTransactionExpired if the chain passes the expiration
+// without including the transaction.
+func (h *HiveRpcNode) BroadcastSync(ops []HiveOperation, signer Signer, waitIrreversible bool, timeout time.Duration) (*BroadcastConfirmation, error) {
+	if h.noBroadcast {
+		return nil, errors.New("cannot confirm a transaction while broadcasting is disabled")
+	}
+
+	tx, txId, err := h.signTx(ops, signer)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = h.broadcastTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.waitForTransaction(txId, tx.Expiration, waitIrreversible, timeout)
+}
+
+func (h *HiveRpcNode) waitForTransaction(txId string, expiration string, waitIrreversible bool, timeout time.Duration) (*BroadcastConfirmation, error) {
+	exp, err := time.Parse(customTimeLayout, expiration)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(timeout)
+	conf := &BroadcastConfirmation{TxId: txId}
+	for {
+		// props are read before the lookup so a miss is conclusive up to the
+		// head block they describe
+		props, err := h.getGlobalProps()
+		if err == nil {
+			inclusion, err := h.getTransactionInclusion(txId)
+			if err == nil && inclusion.BlockNum > 0 {
+				conf.BlockNum = inclusion.BlockNum
+				conf.TrxNum = inclusion.TransactionNum
+				conf.Irreversible = inclusion.BlockNum <= props.LastIrreversibleBlockNum
+				if conf.Irreversible || !waitIrreversible {
+					return conf, nil
+				}
+			} else {
+				// not found, or dropped from a reversible block by a fork
+				conf.BlockNum, conf.TrxNum = 0, 0
+
+				headTime, err := time.Parse(customTimeLayout, props.Time)
+				if err == nil && headTime.After(exp) {
+					return conf, ErrTransactionExpired
+				}
+			}
+		}
+
+		if time.Now().Add(confirmPollInterval).After(deadline) {
+			return conf, ErrConfirmTimeout
+		}
+		time.Sleep(confirmPollInterval)
+	}
+}
+
+// BroadcastWithRetry signs ops once and keeps re-sending that same transaction
+// every retryInterval until a node accepts it or it expires, so a timed out
+// attempt can never cause a second, different transaction to land. A node
+// reporting the transaction as a duplicate counts as success, and after each
+// failed attempt the transaction's status is checked before trying again.
+// Only validation failures, which would fail again on every node, are
+// returned immediately; other node errors such as timeouts or a busy
+// database are retried. A retryInterval of zero or less waits one block.
+func (h *HiveRpcNode) BroadcastWithRetry(ops []HiveOperation, signer Signer, retryInterval time.Duration) (string, error) {
+	if retryInterval <= 0 {
+		retryInterval = defaultRetryInterval
+	}
+
+	tx, txId, err := h.signTx(ops, signer)
+	if err != nil {
+		return "", err
+	}
+	if h.noBroadcast {
+		return txId, nil
+	}
+
+	exp, err := time.Parse(customTimeLayout, tx.Expiration)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		_, err := h.broadcastTx(tx)
+		if err == nil || isDuplicateTransactionError(err) {
+			return txId, nil
+		}
+
+		if isValidationError(err) {
+			return "", err
+		}
+
+		included, expired := h.transactionLanded(txId, tx.Expiration, exp)
+		if included {
+			return txId, nil
+		}
+		if expired {
+			return "", fmt.Errorf("%w (last error: %v)", ErrTransactionExpired, err)
+		}
+		time.Sleep(retryInterval)
+	}
+}
+
+func isDuplicateTransactionError(err error) bool {
+	var rpcErr *RpcError
+	return errors.As(err, &rpcErr) && strings.Contains(strings.ToLower(rpcErr.Message), "duplicate transaction")
+}
+
+// validationErrors are fragments of hived's messages for transactions that
+// fail validation, and so can never be accepted no matter how often they are
+// sent
+var validationErrors = []string{
+	"assert exception",
+	"missing required",
+	"missing authority",
+	"irrelevant signature",
+	"duplicate signature",
+}
+
+func isValidationError(err error) bool {
+	var rpcErr *RpcError
+	if !errors.As(err, &rpcErr) {
+		return false
+	}
+	msg := strings.ToLower(rpcErr.Message)
+	for _, fragment := range validationErrors {
+		if strings.Contains(msg, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// transactionLanded reports whether a node already has the transaction, or
+// whether it has expired and can no longer be included
+func (h *HiveRpcNode) transactionLanded(txId string, expiration string, exp time.Time) (included bool, expired bool) {
+	status, err := h.FindTransaction(txId, expiration)
+	if err == nil {
+		switch status.Status {
+		case TxStatusWithinMempool, TxStatusWithinReversibleBlock, TxStatusWithinIrreversibleBlock:
+			return true, false
+		case TxStatusExpiredReversible, TxStatusExpiredIrreversible, TxStatusTooOld:
+			return false, true
+		}
+		return false, false
+	}
+
+	// transaction_status_api is optional on nodes, fall back to account history
+	if inclusion, err := h.getTransactionInclusion(txId); err == nil && inclusion.BlockNum > 0 {
+		return true, false
+	}
+	return false, time.Now().After(exp)
+}
+
+// signTx builds a transaction for ops against the current chain state and
+// signs it, returning the transaction ready for broadcast and its id
+func (h *HiveRpcNode) signTx(ops []HiveOperation, signer Signer) (HiveTransaction, string, error) {
+	if h.netErr != nil {
+		return HiveTransaction{}, "", h.netErr
+	}
+	for _, op := range ops {
+		if err := op.Validate(); err != nil {
+			return HiveTransaction{}, "", err
+		}
+	}
+
+	if h.rcCheck {
+		if err := h.checkRC(ops); err != nil {
+			return HiveTransaction{}, "", err
+		}
+	}
+
+	signingData, err := h.getSigningData()
+	if err != nil {
+		return HiveTransaction{}, "", err
+	}
+	tx := HiveTransaction{
 		RefBlockNum:    signingData.refBlockNum,
 		RefBlockPrefix: signingData.refBlockPrefix,
 		Expiration:     signingData.expiration,
 		Operations:     ops,
+		network:        h.network(),
 	}
 
-	message, err := serializeTx(tx)
+	err = tx.validateAt(signingData.headTime)
+	if err != nil {
+		return HiveTransaction{}, "", err
+	}
 
+	txId, err := tx.TxId()
 	if err != nil {
-		return "", err
+		return HiveTransaction{}, "", err
+	}
+
+	err = tx.Sign(signer)
+	if err != nil {
+		return HiveTransaction{}, "", err
 	}
 
-	digest := hashTxForSig(message)
+	tx.prepareJson()
+
+	return tx, txId, nil
+}
+
+// TxId returns the id the chain will assign to the transaction
+func (t *HiveTransaction) TxId() (string, error) {
+	return t.generateTrxId()
+}
+
+// Sign adds a signature from each of signer's keys
+func (t *HiveTransaction) Sign(signer Signer) error {
+	digest, err := t.Digest()
+	if err != nil {
+		return err
+	}
 
-	txId, err := tx.generateTrxId()
+	sigs, err := signer.SignDigest(digest)
+	if err != nil {
+		return err
+	}
+
+	for _, sig := range sigs {
+		t.Signatures = append(t.Signatures, hex.EncodeToString(sig))
+	}
+	return nil
+}
+
+// BroadcastTransaction validates and broadcasts an already signed transaction,
+// such as one from ParseTransactionJson, and returns its id
+func (h *HiveRpcNode) BroadcastTransaction(tx *HiveTransaction) (string, error) {
+	if tx.network.orMainnet().ChainId != h.network().ChainId {
+		return "", errors.New("transaction was built for a different network")
+	}
+
+	props, err := h.getGlobalProps()
+	if err != nil {
+		return "", err
+	}
+	headTime, err := time.Parse(customTimeLayout, props.Time)
 	if err != nil {
 		return "", err
 	}
-	sig, err := SignDigest(digest, wif)
+	err = tx.validateAt(headTime)
 	if err != nil {
 		return "", err
 	}
 
-	tx.Signatures = append(tx.Signatures, hex.EncodeToString(sig))
+	txId, err := tx.TxId()
+	if err != nil {
+		return "", err
+	}
 
 	tx.prepareJson()
-
-	var params []interface{}
-	params = append(params, tx)
-	if !h.NoBroadcast {
-		q := hrpcQuery{"condenser_api.broadcast_transaction", params}
-		res, err := h.rpcExec(h.address, q)
+	if !h.noBroadcast {
+		res, err := h.broadcastTx(*tx)
 		if err != nil {
 			return string(res), err
 		}
@@ -83,3 +347,10 @@ func (h *HiveRpcNode) Broadcast(ops []HiveOperation, wif *string) (string, error
 
 	return txId, nil
 }
+
+func (h *HiveRpcNode) broadcastTx(tx HiveTransaction) ([]byte, error) {
+	var params []interface{}
+	params = append(params, tx)
+	q := hrpcQuery{"condenser_api.broadcast_transaction", params}
+	return h.rpcExec(q)
+}
@@ -1,6 +1,12 @@
 package hivego
 
-import "testing"
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
 
 func TestGenerateTrxIdHiveTransaction(t *testing.T) {
 	tx := getTestVoteTx()
@@ -21,3 +27,207 @@ func TestPrepareJsonHiveTransaction(t *testing.T) {
 		t.Error("Expected", expected, "got", got)
 	}
 }
+
+func TestDryRun(t *testing.T) {
+	node, h := newTestNode(t)
+	signer, _ := NewKeySigner("5JuMt237G3m3BaT7zH4YdoycUtbw4AEPy6DLdCrKAnFGAtXyQ1W")
+	keyPair, _ := KeyPairFromWif("5JuMt237G3m3BaT7zH4YdoycUtbw4AEPy6DLdCrKAnFGAtXyQ1W")
+
+	res, err := h.DryRun([]HiveOperation{getTestVoteOp()}, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(node.broadcastOps()) != 0 {
+		t.Error("Expected nothing to be broadcast")
+	}
+
+	if len(res.SigningKeys) != 1 || res.SigningKeys[0] != *keyPair.GetPublicKeyString() {
+		t.Error("Expected signing key", *keyPair.GetPublicKeyString(), "got", res.SigningKeys)
+	}
+
+	parsed, err := ParseTransactionJson(res.Json)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txId, _ := parsed.TxId()
+	if txId != res.TxId {
+		t.Error("Expected", res.TxId, "got", txId)
+	}
+
+	// 34 bytes unsigned (10 TaPoS/expiration, 1 op count, 22 vote, 1 extensions)
+	// plus a signature count and one 65 byte signature
+	if res.Size != 100 {
+		t.Error("Expected", 100, "got", res.Size)
+	}
+}
+
+func TestBroadcastWithRetry(t *testing.T) {
+	node, h := newTestNode(t)
+	signer, _ := NewKeySigner("5JuMt237G3m3BaT7zH4YdoycUtbw4AEPy6DLdCrKAnFGAtXyQ1W")
+
+	node.handle("condenser_api.broadcast_transaction", func(json.RawMessage) (interface{}, error) {
+		return nil, errors.New("Duplicate transaction check failed")
+	})
+	txId, err := h.BroadcastWithRetry([]HiveOperation{getTestVoteOp()}, signer, time.Millisecond)
+	if err != nil || len(txId) != 40 {
+		t.Error("Expected duplicate to count as success, got", txId, err)
+	}
+
+	node.handle("condenser_api.broadcast_transaction", func(json.RawMessage) (interface{}, error) {
+		return nil, errors.New("missing required posting authority")
+	})
+	_, err = h.BroadcastWithRetry([]HiveOperation{getTestVoteOp()}, signer, time.Millisecond)
+	var rpcErr *RpcError
+	if !errors.As(err, &rpcErr) {
+		t.Error("Expected the node's rejection to be returned, got", err)
+	}
+}
+
+func TestBroadcastWithRetryTransientErrors(t *testing.T) {
+	node, h := newTestNode(t)
+	signer, _ := NewKeySigner("5JuMt237G3m3BaT7zH4YdoycUtbw4AEPy6DLdCrKAnFGAtXyQ1W")
+	node.handle("transaction_status_api.find_transaction", func(json.RawMessage) (interface{}, error) {
+		return TransactionStatusResult{Status: TxStatusUnknown}, nil
+	})
+
+	// node errors that are not validation failures are retried
+	var mu sync.Mutex
+	attempts := 0
+	node.handle("condenser_api.broadcast_transaction", func(json.RawMessage) (interface{}, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		switch attempts {
+		case 1:
+			return nil, errors.New("Unable to acquire database lock")
+		case 2:
+			return nil, errors.New("Request timed out")
+		}
+		return map[string]interface{}{}, nil
+	})
+	txId, err := h.BroadcastWithRetry([]HiveOperation{getTestVoteOp()}, signer, time.Millisecond)
+	if err != nil || len(txId) != 40 || attempts != 3 {
+		t.Error("Expected success on the third attempt, got", txId, err, attempts)
+	}
+
+	// a transaction found after an error is not sent again
+	attempts = 0
+	node.handle("condenser_api.broadcast_transaction", func(json.RawMessage) (interface{}, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		return nil, errors.New("Request timed out")
+	})
+	node.handle("transaction_status_api.find_transaction", func(json.RawMessage) (interface{}, error) {
+		return TransactionStatusResult{Status: TxStatusWithinMempool}, nil
+	})
+	txId, err = h.BroadcastWithRetry([]HiveOperation{getTestVoteOp()}, signer, time.Millisecond)
+	if err != nil || len(txId) != 40 || attempts != 1 {
+		t.Error("Expected the landed transaction after one attempt, got", txId, err, attempts)
+	}
+}
+
+// syncTestNode serves the chain state BroadcastSync polls. Once the
+// transaction has been looked up, the last irreversible block moves to
+// libAfterLookup when that is set.
+type syncTestNode struct {
+	mu             sync.Mutex
+	headTime       string
+	lib            int
+	libAfterLookup int
+	included       int
+}
+
+func newSyncTestNode(t *testing.T) (*syncTestNode, *testNode, *HiveRpcNode) {
+	node, h := newTestNode(t)
+	s := &syncTestNode{headTime: "2016-08-08T12:23:47", lib: 4463660}
+	node.handle("condenser_api.get_dynamic_global_properties", func(json.RawMessage) (interface{}, error) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return globalProps{
+			HeadBlockNumber:          4463677,
+			HeadBlockId:              "00441c3d5fe26f45af2c94edb1b69dbdd77d8ba0",
+			LastIrreversibleBlockNum: s.lib,
+			Time:                     s.headTime,
+		}, nil
+	})
+	node.handle("account_history_api.get_transaction", func(json.RawMessage) (interface{}, error) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.libAfterLookup != 0 {
+			s.lib = s.libAfterLookup
+		}
+		if s.included == 0 {
+			return nil, errors.New("Unknown Transaction")
+		}
+		return map[string]interface{}{"block_num": s.included, "transaction_num": 2}, nil
+	})
+	return s, node, h
+}
+
+func TestBroadcastSync(t *testing.T) {
+	s, node, h := newSyncTestNode(t)
+	signer, _ := NewKeySigner("5JuMt237G3m3BaT7zH4YdoycUtbw4AEPy6DLdCrKAnFGAtXyQ1W")
+	s.included = 4463670
+
+	conf, err := h.BroadcastSync([]HiveOperation{getTestVoteOp()}, signer, false, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conf.TxId) != 40 || conf.BlockNum != 4463670 || conf.TrxNum != 2 || conf.Irreversible {
+		t.Error("Unexpected confirmation", conf)
+	}
+	if len(node.broadcastOps()) != 1 {
+		t.Error("Expected one broadcast, got", len(node.broadcastOps()))
+	}
+}
+
+func TestBroadcastSyncIrreversible(t *testing.T) {
+	s, _, h := newSyncTestNode(t)
+	signer, _ := NewKeySigner("5JuMt237G3m3BaT7zH4YdoycUtbw4AEPy6DLdCrKAnFGAtXyQ1W")
+	s.included = 4463670
+	s.libAfterLookup = 4463680
+
+	conf, err := h.BroadcastSync([]HiveOperation{getTestVoteOp()}, signer, true, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conf.BlockNum != 4463670 || !conf.Irreversible {
+		t.Error("Expected an irreversible confirmation, got", conf)
+	}
+}
+
+func TestBroadcastSyncTimeout(t *testing.T) {
+	s, _, h := newSyncTestNode(t)
+	signer, _ := NewKeySigner("5JuMt237G3m3BaT7zH4YdoycUtbw4AEPy6DLdCrKAnFGAtXyQ1W")
+
+	conf, err := h.BroadcastSync([]HiveOperation{getTestVoteOp()}, signer, false, 10*time.Millisecond)
+	if !errors.Is(err, ErrConfirmTimeout) || conf == nil || conf.BlockNum != 0 {
+		t.Error("Expected ErrConfirmTimeout, got", conf, err)
+	}
+
+	// included but never irreversible
+	s.included = 4463670
+	conf, err = h.BroadcastSync([]HiveOperation{getTestVoteOp()}, signer, true, 10*time.Millisecond)
+	if !errors.Is(err, ErrConfirmTimeout) || conf == nil || conf.BlockNum != 4463670 || conf.Irreversible {
+		t.Error("Expected ErrConfirmTimeout with the reversible inclusion, got", conf, err)
+	}
+}
+
+func TestBroadcastSyncExpired(t *testing.T) {
+	s, node, h := newSyncTestNode(t)
+	signer, _ := NewKeySigner("5JuMt237G3m3BaT7zH4YdoycUtbw4AEPy6DLdCrKAnFGAtXyQ1W")
+
+	// the chain moves past the expiration as soon as the transaction is sent
+	node.handle("condenser_api.broadcast_transaction", func(json.RawMessage) (interface{}, error) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.headTime = "2016-08-08T12:30:00"
+		return map[string]interface{}{}, nil
+	})
+
+	_, err := h.BroadcastSync([]HiveOperation{getTestVoteOp()}, signer, false, time.Minute)
+	if !errors.Is(err, ErrTransactionExpired) {
+		t.Error("Expected ErrTransactionExpired, got", err)
+	}
+}
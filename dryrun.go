@@ -0,0 +1,72 @@
+package hivego
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// DryRunResult is everything about a signed transaction that a broadcast
+// would send, for inspection without sending it
+type DryRunResult struct {
+	TxId        string
+	Transaction HiveTransaction
+	// Json is the signed transaction exactly as it would be broadcast
+	Json []byte
+	// SerializedHex is the binary serialization the signatures cover, without
+	// the chain id prefix
+	SerializedHex string
+	// Digest is the hex encoded digest that was signed
+	Digest      string
+	SigningKeys []string
+	// Size is the serialized size of the signed transaction in bytes
+	Size int
+}
+
+// DryRun builds and signs a transaction for ops exactly as Broadcast would,
+// but returns it instead of sending it
+func (h *HiveRpcNode) DryRun(ops []HiveOperation, signer Signer) (*DryRunResult, error) {
+	tx, txId, err := h.signTx(ops, signer)
+	if err != nil {
+		return nil, err
+	}
+	return tx.dryRun(txId)
+}
+
+func (t *HiveTransaction) dryRun(txId string) (*DryRunResult, error) {
+	serialized, err := serializeTx(*t)
+	if err != nil {
+		return nil, err
+	}
+	digest, err := t.Digest()
+	if err != nil {
+		return nil, err
+	}
+
+	jsonB, err := json.Marshal(t)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := t.SigningKeys()
+	if err != nil {
+		return nil, err
+	}
+	var keyStrs []string
+	for _, key := range keys {
+		keyStrs = append(keyStrs, *t.network.PublicKeyString(key))
+	}
+
+	sigCount := make([]byte, binary.MaxVarintLen64)
+	size := len(serialized) + binary.PutUvarint(sigCount, uint64(len(t.Signatures))) + 65*len(t.Signatures)
+
+	return &DryRunResult{
+		TxId:          txId,
+		Transaction:   *t,
+		Json:          jsonB,
+		SerializedHex: hex.EncodeToString(serialized),
+		Digest:        hex.EncodeToString(digest),
+		SigningKeys:   keyStrs,
+		Size:          size,
+	}, nil
+}
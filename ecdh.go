@@ -0,0 +1,166 @@
+package hivego
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"io"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v2"
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	payloadVersion = 1
+	payloadInfo    = "hivego payload aes-256-gcm"
+	// version, sender key, recipient key, nonce
+	payloadHeaderLen = 1 + 33 + 33 + 12
+)
+
+// SharedSecret returns the ECDH secret of key and another party's public key,
+// sha512 of the shared point's x coordinate as used by encrypted memos. Both
+// parties derive the same secret, key's owner with pub and pub's owner with
+// key's public key.
+func SharedSecret(key *KeyPair, pub *secp256k1.PublicKey) [64]byte {
+	return sharedSecret(key.PrivateKey, pub)
+}
+
+// SharedSecret is SharedSecret for a PrivateKey
+func (k *PrivateKey) SharedSecret(pub *secp256k1.PublicKey) ([64]byte, error) {
+	var secret [64]byte
+	err := k.withKey(func(key *secp256k1.PrivateKey) error {
+		secret = sharedSecret(key, pub)
+		return nil
+	})
+	return secret, err
+}
+
+// EncryptPayload encrypts payload from key to another party's public key,
+// typically their on-chain memo key, with AES-256-GCM under a key derived from
+// their shared secret. The result names both keys, so either party can
+// decrypt it with DecryptPayload, and can be carried in e.g. a custom_json
+// after encoding it as base64 or hex.
+func EncryptPayload(payload []byte, key KeyHolder, to *secp256k1.PublicKey) ([]byte, error) {
+	var data []byte
+	err := key.withKey(func(priv *secp256k1.PrivateKey) error {
+		var err error
+		data, err = encryptPayload(payload, priv, to)
+		return err
+	})
+	return data, err
+}
+
+// EncryptPayload is EncryptPayload for a PrivateKey
+func (k *PrivateKey) EncryptPayload(payload []byte, to *secp256k1.PublicKey) ([]byte, error) {
+	return EncryptPayload(payload, k, to)
+}
+
+func encryptPayload(payload []byte, key *secp256k1.PrivateKey, to *secp256k1.PublicKey) ([]byte, error) {
+	nonce := make([]byte, 12)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	var header bytes.Buffer
+	header.WriteByte(payloadVersion)
+	header.Write(key.PubKey().SerializeCompressed())
+	header.Write(to.SerializeCompressed())
+	header.Write(nonce)
+
+	aead, err := payloadAead(key, to)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(header.Bytes(), nonce, payload, header.Bytes()), nil
+}
+
+// DecryptPayload decrypts a payload from EncryptPayload with the key of
+// either party, returning it along with the other party's public key. A
+// payload that decrypts was made by the holder of one of the two keys.
+func DecryptPayload(data []byte, key KeyHolder) ([]byte, *secp256k1.PublicKey, error) {
+	var plain []byte
+	var other *secp256k1.PublicKey
+	err := key.withKey(func(priv *secp256k1.PrivateKey) error {
+		var err error
+		plain, other, err = decryptPayload(data, priv)
+		return err
+	})
+	return plain, other, err
+}
+
+// DecryptPayload is DecryptPayload for a PrivateKey
+func (k *PrivateKey) DecryptPayload(data []byte) ([]byte, *secp256k1.PublicKey, error) {
+	return DecryptPayload(data, k)
+}
+
+func decryptPayload(data []byte, key *secp256k1.PrivateKey) ([]byte, *secp256k1.PublicKey, error) {
+	if len(data) < payloadHeaderLen || data[0] != payloadVersion {
+		return nil, nil, errors.New("invalid encrypted payload")
+	}
+	from, err := secp256k1.ParsePubKey(data[1:34])
+	if err != nil {
+		return nil, nil, err
+	}
+	to, err := secp256k1.ParsePubKey(data[34:67])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var other *secp256k1.PublicKey
+	switch pub := key.PubKey(); {
+	case pub.IsEqual(from):
+		other = to
+	case pub.IsEqual(to):
+		other = from
+	default:
+		return nil, nil, errors.New("payload was not encrypted for this key")
+	}
+
+	aead, err := payloadAead(key, other)
+	if err != nil {
+		return nil, nil, err
+	}
+	header := data[:payloadHeaderLen]
+	plain, err := aead.Open(nil, data[67:payloadHeaderLen], data[payloadHeaderLen:], header)
+	if err != nil {
+		return nil, nil, err
+	}
+	return plain, other, nil
+}
+
+// EncryptPayloadFor is EncryptPayload to account's on-chain memo key, with key
+// normally the sender's memo key
+func (h *HiveRpcNode) EncryptPayloadFor(account string, payload []byte, key KeyHolder) ([]byte, error) {
+	accounts, err := h.GetAccount([]string{account})
+	if err != nil {
+		return nil, err
+	}
+	if len(accounts) == 0 {
+		return nil, errors.New("account not found: " + account)
+	}
+	to, err := accounts[0].MemoKey.Key()
+	if err != nil {
+		return nil, err
+	}
+	return EncryptPayload(payload, key, to)
+}
+
+func payloadAead(key *secp256k1.PrivateKey, pub *secp256k1.PublicKey) (cipher.AEAD, error) {
+	secret := sharedSecret(key, pub)
+	aesKey := make([]byte, 32)
+	_, err := io.ReadFull(hkdf.New(sha256.New, secret[:], nil, []byte(payloadInfo)), aesKey)
+	zero(secret[:])
+	if err != nil {
+		return nil, err
+	}
+	defer zero(aesKey)
+
+	block, err := aes.NewCipher(aesKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
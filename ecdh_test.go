@@ -0,0 +1,102 @@
+package hivego
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSharedSecret(t *testing.T) {
+	alice := KeyPairFromPassword("alice", RoleMemo, "p")
+	bob := KeyPairFromPassword("bob", RoleMemo, "p")
+
+	secret := SharedSecret(alice, bob.PublicKey)
+	if secret != SharedSecret(bob, alice.PublicKey) {
+		t.Error("Expected both parties to derive the same secret")
+	}
+
+	key, _ := PrivateKeyFromBytes(alice.PrivateKey.Serialize())
+	if got, err := key.SharedSecret(bob.PublicKey); err != nil || got != secret {
+		t.Error("Expected a PrivateKey to derive the same secret, got", err)
+	}
+}
+
+func TestEncryptPayload(t *testing.T) {
+	alice := KeyPairFromPassword("alice", RoleMemo, "p")
+	bob := KeyPairFromPassword("bob", RoleMemo, "p")
+	carol := KeyPairFromPassword("carol", RoleMemo, "p")
+
+	data, err := EncryptPayload([]byte("order #42"), alice, bob.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		key   *KeyPair
+		other *KeyPair
+	}{{bob, alice}, {alice, bob}} {
+		plain, other, err := DecryptPayload(data, tc.key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(plain) != "order #42" || !other.IsEqual(tc.other.PublicKey) {
+			t.Error("Unexpected payload", string(plain), other)
+		}
+	}
+
+	if _, _, err := DecryptPayload(data, carol); err == nil {
+		t.Error("Expected a third party not to decrypt the payload")
+	}
+	tampered := append([]byte{}, data...)
+	tampered[len(tampered)-1] ^= 1
+	if _, _, err := DecryptPayload(tampered, bob); err == nil {
+		t.Error("Expected a tampered payload to fail")
+	}
+	if _, _, err := DecryptPayload(data[:10], bob); err == nil {
+		t.Error("Expected a truncated payload to fail")
+	}
+}
+
+func TestPrivateKeyEncryptPayload(t *testing.T) {
+	alice := KeyPairFromPassword("alice", RoleMemo, "p")
+	bob := KeyPairFromPassword("bob", RoleMemo, "p")
+	aliceKey, _ := PrivateKeyFromBytes(alice.PrivateKey.Serialize())
+	bobKey, _ := PrivateKeyFromBytes(bob.PrivateKey.Serialize())
+
+	// interchangeable with the KeyPair functions in both directions
+	data, err := aliceKey.EncryptPayload([]byte("order #42"), bob.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, other, err := DecryptPayload(data, bob)
+	if err != nil || string(plain) != "order #42" || !other.IsEqual(alice.PublicKey) {
+		t.Error("Expected the KeyPair to decrypt, got", string(plain), err)
+	}
+
+	data, _ = EncryptPayload([]byte("order #43"), alice, bob.PublicKey)
+	plain, other, err = bobKey.DecryptPayload(data)
+	if err != nil || string(plain) != "order #43" || !other.IsEqual(alice.PublicKey) {
+		t.Error("Expected the PrivateKey to decrypt, got", string(plain), err)
+	}
+
+	bobKey.Destroy()
+	if _, _, err = bobKey.DecryptPayload(data); err != ErrKeyDestroyed {
+		t.Error("Expected", ErrKeyDestroyed, "got", err)
+	}
+}
+
+func TestEncryptPayloadFor(t *testing.T) {
+	node, h := newTestNode(t)
+	alice := KeyPairFromPassword("alice", RoleMemo, "p")
+	bob := KeyPairFromPassword("bob", RoleMemo, "p")
+	node.handle("condenser_api.get_accounts", func(json.RawMessage) (interface{}, error) {
+		return []map[string]interface{}{{"name": "bob", "memo_key": *bob.GetPublicKeyString()}}, nil
+	})
+
+	data, err := h.EncryptPayloadFor("bob", []byte("hi"), alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain, _, err := DecryptPayload(data, bob); err != nil || string(plain) != "hi" {
+		t.Error("Expected bob to decrypt the payload, got", string(plain), err)
+	}
+}
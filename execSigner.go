@@ -0,0 +1,105 @@
+package hivego
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v2"
+)
+
+// ExecSigner is a Signer that delegates signing to an external program, so
+// private keys can stay in a separate process or a hardware backed store.
+//
+// For every digest the program is run once and given a JSON request on stdin:
+//
+//	{"digest": "<hex>", "public_keys": ["STM...", ...]}
+//
+// It must answer on stdout with one hex compact signature per public key, in
+// the same order:
+//
+//	{"signatures": ["<hex>", ...]}
+//
+// Returned signatures are checked against the expected public keys, so a
+// misbehaving program cannot make hivego broadcast a bad transaction.
+type ExecSigner struct {
+	pubKeys []*secp256k1.PublicKey
+	// the keys as given, so the program sees the prefix it expects
+	pubKeyStrs []string
+	name       string
+	args       []string
+}
+
+type execSignRequest struct {
+	Digest     string   `json:"digest"`
+	PublicKeys []string `json:"public_keys"`
+}
+
+type execSignResponse struct {
+	Signatures []string `json:"signatures"`
+}
+
+// NewExecSigner returns a Signer running the program name with args for the
+// given public keys
+func NewExecSigner(pubKeys []string, name string, args ...string) (*ExecSigner, error) {
+	s := &ExecSigner{name: name, args: args}
+	for _, pubKey := range pubKeys {
+		key, err := decodeKnownPublicKey(pubKey)
+		if err != nil {
+			return nil, err
+		}
+		s.pubKeys = append(s.pubKeys, key)
+		s.pubKeyStrs = append(s.pubKeyStrs, pubKey)
+	}
+	return s, nil
+}
+
+func (s *ExecSigner) PublicKeys() []*secp256k1.PublicKey {
+	return s.pubKeys
+}
+
+func (s *ExecSigner) SignDigest(digest []byte) ([][]byte, error) {
+	req := execSignRequest{Digest: hex.EncodeToString(digest), PublicKeys: s.pubKeyStrs}
+	reqB, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(s.name, s.args...)
+	cmd.Stdin = bytes.NewReader(reqB)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("signer %s failed: %w: %s", s.name, err, stderr.String())
+	}
+
+	var resp execSignResponse
+	err = json.Unmarshal(stdout.Bytes(), &resp)
+	if err != nil {
+		return nil, fmt.Errorf("signer %s returned invalid output: %w", s.name, err)
+	}
+	if len(resp.Signatures) != len(s.pubKeys) {
+		return nil, fmt.Errorf("signer %s returned %d signatures for %d keys", s.name, len(resp.Signatures), len(s.pubKeys))
+	}
+
+	var sigs [][]byte
+	for i, sigHex := range resp.Signatures {
+		sig, err := hex.DecodeString(sigHex)
+		if err != nil {
+			return nil, err
+		}
+		// checked first, since RecoverPublicKey rejects non-canonical signatures too
+		if !isCanonicalSignature(sig) {
+			return nil, fmt.Errorf("signer %s returned a non-canonical signature for %s", s.name, req.PublicKeys[i])
+		}
+		recovered, err := RecoverPublicKey(digest, sig)
+		if err != nil || !recovered.IsEqual(s.pubKeys[i]) {
+			return nil, fmt.Errorf("signer %s returned a bad signature for %s", s.name, req.PublicKeys[i])
+		}
+		sigs = append(sigs, sig)
+	}
+	return sigs, nil
+}
@@ -11,7 +11,6 @@ require (
 
 require (
 	github.com/andybalholm/brotli v1.0.4 // indirect
-	github.com/cfoxon/hivego v0.0.0-20230410131714-9f5a7f11f8df
 	github.com/decred/dcrd/crypto/blake256 v1.0.0 // indirect
 	github.com/klauspost/compress v1.15.0 // indirect
 	github.com/valyala/bytebufferpool v1.0.0 // indirect
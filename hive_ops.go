@@ -1,13 +1,15 @@
 package hivego
 
 import (
-	"encoding/hex"
+	"errors"
 	"fmt"
 )
 
 type HiveOperation interface {
 	SerializeOp() ([]byte, error)
 	OpName() string
+	// Validate checks the operation against hived's validation rules
+	Validate() error
 }
 
 type voteOperation struct {
@@ -22,10 +24,13 @@ func (o voteOperation) OpName() string {
 	return o.opText
 }
 
-func (h *HiveRpcNode) VotePost(voter string, author string, permlink string, weight int, wif *string) (string, error) {
+func (h *HiveRpcNode) VotePost(voter string, author string, permlink string, weight int, signer Signer) (string, error) {
+	if err := validateVoteWeight("vote", weight); err != nil {
+		return "", err
+	}
 	vote := voteOperation{voter, author, permlink, int16(weight), "vote"}
 
-	return h.Broadcast([]HiveOperation{vote}, wif)
+	return h.Broadcast([]HiveOperation{vote}, signer)
 }
 
 type Auths struct {
@@ -43,11 +48,13 @@ type accountUpdateOperation struct {
 	Active  *Auths `json:"active"`
 	Posting *Auths `json:"posting"`
 
-	MemoKey      string `json:"memo_key"`
-	JsonMetadata string `json:"json_metadata"`
+	MemoKey      PublicKey `json:"memo_key"`
+	JsonMetadata string    `json:"json_metadata"`
 
 	// special (not serialized, used to determine operation ID number)
 	opText string
+	// network the keys are encoded for, nil for mainnet
+	net *NetworkConfig
 }
 
 func (o accountUpdateOperation) OpName() string {
@@ -61,24 +68,29 @@ func (h *HiveRpcNode) UpdateAccount(
 	posting *Auths,
 	jsonMetadata string,
 	memoKey string,
-	wif *string,
+	signer Signer,
 ) (string, error) {
 
 	if owner != nil || active != nil || posting != nil {
 		return "", fmt.Errorf("owner, active, posting are not supported or tested yet")
 	}
+	memoPubKey, err := h.network().ParsePublicKey(memoKey)
+	if err != nil {
+		return "", err
+	}
 
 	op := accountUpdateOperation{
 		Account:      account,
 		Owner:        owner,
 		Active:       active,
 		Posting:      posting,
-		MemoKey:      memoKey,
+		MemoKey:      memoPubKey,
 		JsonMetadata: jsonMetadata,
 		opText:       "account_update",
+		net:          h.network(),
 	}
 
-	return h.Broadcast([]HiveOperation{op}, wif)
+	return h.Broadcast([]HiveOperation{op}, signer)
 }
 
 type customJsonOperation struct {
@@ -93,9 +105,14 @@ func (o customJsonOperation) OpName() string {
 	return o.opText
 }
 
-func (h *HiveRpcNode) BroadcastJson(reqAuth []string, reqPostAuth []string, id string, cj string, wif *string) (string, error) {
-	op := customJsonOperation{reqAuth, reqPostAuth, id, cj, "custom_json"}
-	return h.Broadcast([]HiveOperation{op}, wif)
+// NewCustomJsonOp builds a custom_json operation, e.g. for a Batcher
+func NewCustomJsonOp(reqAuth []string, reqPostAuth []string, id string, cj string) HiveOperation {
+	return customJsonOperation{reqAuth, reqPostAuth, id, cj, "custom_json"}
+}
+
+func (h *HiveRpcNode) BroadcastJson(reqAuth []string, reqPostAuth []string, id string, cj string, signer Signer) (string, error) {
+	op := NewCustomJsonOp(reqAuth, reqPostAuth, id, cj)
+	return h.Broadcast([]HiveOperation{op}, signer)
 }
 
 type claimRewardOperation struct {
@@ -104,13 +121,15 @@ type claimRewardOperation struct {
 	RewardHIVE  string `json:"reward_hive"`
 	RewardVests string `json:"reward_vests"`
 	opText      string
+	// network the assets are written for, nil for mainnet
+	net *NetworkConfig
 }
 
 func (o claimRewardOperation) OpName() string {
 	return o.opText
 }
 
-func (h *HiveRpcNode) ClaimRewards(Account string, wif *string) (string, error) {
+func (h *HiveRpcNode) ClaimRewards(Account string, signer Signer) (string, error) {
 	accountData, err := h.GetAccount([]string{Account})
 
 	if err != nil {
@@ -118,8 +137,8 @@ func (h *HiveRpcNode) ClaimRewards(Account string, wif *string) (string, error)
 	}
 
 	for _, accounts := range accountData {
-		claim := claimRewardOperation{Account, accounts.RewardHbdBalance, accounts.RewardHiveBalance, accounts.RewardVestingBalance, "claim_reward_balance"}
-		broadcast, err := h.Broadcast([]HiveOperation{claim}, wif)
+		claim := claimRewardOperation{Account, accounts.RewardHbdBalance, accounts.RewardHiveBalance, accounts.RewardVestingBalance, "claim_reward_balance", h.network()}
+		broadcast, err := h.Broadcast([]HiveOperation{claim}, signer)
 		return broadcast, err
 	}
 
@@ -133,21 +152,70 @@ type transferOperation struct {
 	Amount string `json:"amount"`
 	Memo   string `json:"memo"`
 	opText string
+	// network the amount is written for, nil for mainnet
+	net *NetworkConfig
 }
 
 func (o transferOperation) OpName() string {
 	return o.opText
 }
 
-func (h *HiveRpcNode) Transfer(from string, to string, amount string, memo string, wif *string) (string, error) {
-	transfer := transferOperation{from, to, amount, memo, "transfer"}
+// ErrMemoKeyUnavailable is returned by Transfer for a memo starting with "#"
+// when the signer does not hold the sender's memo key to encrypt it with
+var ErrMemoKeyUnavailable = errors.New("memo starting with # needs the sender's memo key to be encrypted, use TransferEncrypted")
+
+// Transfer sends amount from one account to another. A memo starting with
+// "#" is encrypted to the recipient's on-chain memo key with the sender's
+// memo key, which the signer must hold; it is never broadcast in plaintext.
+func (h *HiveRpcNode) Transfer(from string, to string, amount string, memo string, signer Signer) (string, error) {
+	if isPlaintextPrivateMemo(memo) {
+		source, ok := signer.(memoKeySource)
+		if !ok {
+			return "", ErrMemoKeyUnavailable
+		}
+		accounts, err := h.GetAccount([]string{from})
+		if err != nil {
+			return "", err
+		}
+		if len(accounts) == 0 {
+			return "", errors.New("account not found: " + from)
+		}
+		pub, err := accounts[0].MemoKey.Key()
+		if err != nil {
+			return "", err
+		}
+		memoKey := source.memoKey(pub)
+		if memoKey == nil {
+			return "", ErrMemoKeyUnavailable
+		}
+		return h.TransferEncrypted(from, to, amount, memo, memoKey, signer)
+	}
 
-	return h.Broadcast([]HiveOperation{transfer}, wif)
+	transfer := transferOperation{from, to, amount, memo, "transfer", h.network()}
+
+	return h.Broadcast([]HiveOperation{transfer}, signer)
 }
 
-func getHiveChainId() []byte {
-	cid, _ := hex.DecodeString("beeab0de00000000000000000000000000000000000000000000000000000000")
-	return cid
+// TransferEncrypted is Transfer with a memo starting with "#" encrypted from
+// memoKey, the sender's memo key, to the recipient's on-chain memo key
+func (h *HiveRpcNode) TransferEncrypted(from string, to string, amount string, memo string, memoKey KeyHolder, signer Signer) (string, error) {
+	accounts, err := h.GetAccount([]string{to})
+	if err != nil {
+		return "", err
+	}
+	if len(accounts) == 0 {
+		return "", errors.New("account not found: " + to)
+	}
+	toKey, err := accounts[0].MemoKey.Key()
+	if err != nil {
+		return "", err
+	}
+
+	memo, err = EncryptMemo(memo, memoKey, toKey)
+	if err != nil {
+		return "", err
+	}
+	return h.Transfer(from, to, amount, memo, signer)
 }
 
 func getHiveOpId(op string) uint64 {
@@ -1,23 +1,109 @@
 package hivego
 
 import (
-	"errors"
+	"encoding/json"
+	"fmt"
+	"math"
 	"strconv"
+	"sync"
+	"time"
 
 	"github.com/cfoxon/jsonrpc2client"
 )
 
+// block fetches are always spread over at least this many connections and
+// batch size, whatever the node was configured with
+const (
+	minBlockConn  = 10
+	minBlockBatch = 4
+)
+
+// HiveRpcNode is a client for a single Hive API node. It is safe for
+// concurrent use by multiple goroutines: its configuration is fixed by the
+// options passed to NewHiveRpc and the underlying RPC clients are created once
+// and shared by all calls.
 type HiveRpcNode struct {
-	address     string
-	MaxConn     int
-	MaxBatch    int
-	NoBroadcast bool
+	maxConn         int
+	maxBatch        int
+	noBroadcast     bool
+	txExpiration    time.Duration
+	refIrreversible bool
+	rcCheck         bool
+	net             NetworkConfig
+	netErr          error
+
+	client rpcCaller
+}
+
+// rpcCaller is the part of jsonrpc2client's client hivego uses
+type rpcCaller interface {
+	CallRaw(request *jsonrpc2client.RpcRequest) (*jsonrpc2client.RpcResponse, error)
+	CallBatchRaw(requests jsonrpc2client.RPCRequests) (jsonrpc2client.RpcResponses, error)
+}
+
+// HiveRpcOption configures a HiveRpcNode at construction
+type HiveRpcOption func(*HiveRpcNode)
+
+// WithNoBroadcast makes the node sign transactions without broadcasting them
+func WithNoBroadcast() HiveRpcOption {
+	return func(h *HiveRpcNode) {
+		h.noBroadcast = true
+	}
+}
+
+// WithTxExpiration sets how long after the node's head block time a broadcast
+// transaction expires. Zero means the default of 30 seconds; the chain allows
+// at most one hour.
+func WithTxExpiration(d time.Duration) HiveRpcOption {
+	return func(h *HiveRpcNode) {
+		h.txExpiration = d
+	}
+}
+
+// WithRefIrreversible makes transactions reference the last irreversible
+// block for TaPoS instead of the head block, so a fork cannot invalidate them
+func WithRefIrreversible() HiveRpcOption {
+	return func(h *HiveRpcNode) {
+		h.refIrreversible = true
+	}
+}
+
+// WithCheckRC makes broadcasts estimate the transaction's resource credit
+// cost first and fail with an InsufficientRCError if the payer cannot afford it
+func WithCheckRC() HiveRpcOption {
+	return func(h *HiveRpcNode) {
+		h.rcCheck = true
+	}
+}
+
+// WithNetwork selects the chain transactions are signed for, along with its
+// key prefix and asset symbols. The config is copied, so later changes to it
+// do not affect the node. The default is MainnetConfig. An invalid config,
+// see NetworkConfig.Validate, makes every signing call fail.
+func WithNetwork(net NetworkConfig) HiveRpcOption {
+	return func(h *HiveRpcNode) {
+		h.net = net
+		h.netErr = net.Validate()
+	}
 }
 
 type globalProps struct {
-	HeadBlockNumber int    `json:"head_block_number"`
-	HeadBlockId     string `json:"head_block_id"`
-	Time            string `json:"time"`
+	HeadBlockNumber          int    `json:"head_block_number"`
+	HeadBlockId              string `json:"head_block_id"`
+	LastIrreversibleBlockNum int    `json:"last_irreversible_block_num"`
+	Time                     string `json:"time"`
+	TotalVestingShares       string `json:"total_vesting_shares"`
+}
+
+// RpcError is an error returned by the node itself, as opposed to a failure
+// to reach it
+type RpcError struct {
+	Code    int
+	Message string
+}
+
+func (e *RpcError) Error() string {
+	return strconv.Itoa(e.Code) + "    " + e.Message
 }
 
 type hrpcQuery struct {
@@ -25,57 +111,148 @@ type hrpcQuery struct {
 	params interface{}
 }
 
-func NewHiveRpc(addr string) *HiveRpcNode {
-	return NewHiveRpcWithOpts(addr, 1, 1)
+func NewHiveRpc(addr string, opts ...HiveRpcOption) *HiveRpcNode {
+	return NewHiveRpcWithOpts(addr, 1, 1, opts...)
 }
 
-func NewHiveRpcWithOpts(addr string, maxConn int, maxBatch int) *HiveRpcNode {
-	return &HiveRpcNode{address: addr,
-		MaxConn:  maxConn,
-		MaxBatch: maxBatch,
+func NewHiveRpcWithOpts(addr string, maxConn int, maxBatch int, opts ...HiveRpcOption) *HiveRpcNode {
+	h := &HiveRpcNode{
+		maxConn:  maxConn,
+		maxBatch: maxBatch,
+		net:      MainnetConfig,
 	}
+	if h.maxConn < 1 {
+		h.maxConn = 1
+	}
+	if h.maxBatch < 1 {
+		h.maxBatch = 1
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	// jsonrpc2client's workers append to a shared slice without locking, so
+	// batches are split and sent concurrently by rpcExecBatch instead, each by
+	// a single worker that never splits them further
+	h.client = jsonrpc2client.NewClientWithOpts(addr, 1, math.MaxInt32)
+	return h
 }
 
 func (h *HiveRpcNode) GetDynamicGlobalProps() ([]byte, error) {
 	q := hrpcQuery{method: "condenser_api.get_dynamic_global_properties", params: []string{}}
-	res, err := h.rpcExec(h.address, q)
+	res, err := h.rpcExec(q)
 	if err != nil {
 		return nil, err
 	}
 	return res, nil
 }
 
-func (h *HiveRpcNode) rpcExec(endpoint string, query hrpcQuery) ([]byte, error) {
-	rpcClient := jsonrpc2client.NewClientWithOpts(endpoint, h.MaxConn, h.MaxBatch)
+func (h *HiveRpcNode) getGlobalProps() (globalProps, error) {
+	propsB, err := h.GetDynamicGlobalProps()
+	if err != nil {
+		return globalProps{}, err
+	}
+
+	var props globalProps
+	err = json.Unmarshal(propsB, &props)
+	if err != nil {
+		return globalProps{}, err
+	}
+	return props, nil
+}
+
+func (h *HiveRpcNode) rpcExec(query hrpcQuery) ([]byte, error) {
 	jr2query := &jsonrpc2client.RpcRequest{Method: query.method, JsonRpc: "2.0", Id: 1, Params: query.params}
-	resp, err := rpcClient.CallRaw(jr2query)
+	resp, err := h.client.CallRaw(jr2query)
 	if err != nil {
 		return nil, err
 	}
 
 	if resp.Error != nil {
-		return nil, errors.New(strconv.Itoa(resp.Error.Code) + "    " + resp.Error.Message)
+		return nil, &RpcError{Code: resp.Error.Code, Message: resp.Error.Message}
 	}
 
 	return resp.Result, nil
 }
 
-func (h *HiveRpcNode) rpcExecBatchFast(endpoint string, queries []hrpcQuery) ([][]byte, error) {
-	rpcClient := jsonrpc2client.NewClientWithOpts(endpoint, h.MaxConn, h.MaxBatch)
+// rpcExecInto executes query and decodes its result into v
+func (h *HiveRpcNode) rpcExecInto(query hrpcQuery, v interface{}) error {
+	res, err := h.rpcExec(query)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(res, v)
+}
+
+// rpcExecBatch executes queries in batches of up to maxBatch, sending up to
+// maxConn batches at once, and returns their results in query order
+func (h *HiveRpcNode) rpcExecBatch(queries []hrpcQuery) ([]json.RawMessage, error) {
+	return h.rpcExecBatchWith(queries, h.maxConn, h.maxBatch)
+}
+
+// rpcExecBlockBatch is rpcExecBatch for block fetches, which always use at
+// least minBlockConn connections and batches of minBlockBatch
+func (h *HiveRpcNode) rpcExecBlockBatch(queries []hrpcQuery) ([]json.RawMessage, error) {
+	maxConn, maxBatch := h.maxConn, h.maxBatch
+	if maxConn < minBlockConn {
+		maxConn = minBlockConn
+	}
+	if maxBatch < minBlockBatch {
+		maxBatch = minBlockBatch
+	}
+	return h.rpcExecBatchWith(queries, maxConn, maxBatch)
+}
 
-	var jr2queries jsonrpc2client.RPCRequests
+func (h *HiveRpcNode) rpcExecBatchWith(queries []hrpcQuery, maxConn int, maxBatch int) ([]json.RawMessage, error) {
+	var batches []jsonrpc2client.RPCRequests
 	for i, query := range queries {
+		if i%maxBatch == 0 {
+			batches = append(batches, nil)
+		}
 		jr2query := &jsonrpc2client.RpcRequest{Method: query.method, JsonRpc: "2.0", Id: i, Params: query.params}
-		jr2queries = append(jr2queries, jr2query)
+		batches[len(batches)-1] = append(batches[len(batches)-1], jr2query)
 	}
 
-	resps, err := rpcClient.CallBatchFast(jr2queries)
-	if err != nil {
-		return nil, err
+	results := make([]json.RawMessage, len(queries))
+	errs := make([]error, len(batches))
+	sem := make(chan struct{}, maxConn)
+	var wg sync.WaitGroup
+	for i, batch := range batches {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, batch jsonrpc2client.RPCRequests) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			// each goroutine writes only the results of its own queries
+			resps, err := h.client.CallBatchRaw(batch)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			for _, resp := range resps {
+				if resp.Error != nil {
+					errs[i] = &RpcError{Code: resp.Error.Code, Message: resp.Error.Message}
+					return
+				}
+				if resp.ID < batch[0].Id || resp.ID > batch[len(batch)-1].Id {
+					errs[i] = fmt.Errorf("unexpected response id %d", resp.ID)
+					return
+				}
+				results[resp.ID] = resp.Result
+			}
+		}(i, batch)
 	}
+	wg.Wait()
 
-	var batchResult [][]byte
-	batchResult = append(batchResult, resps...)
-
-	return batchResult, nil
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	for i, result := range results {
+		if result == nil {
+			return nil, fmt.Errorf("no response to %s request %d", queries[i].method, i)
+		}
+	}
+	return results, nil
 }
@@ -0,0 +1,151 @@
+package hivego
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// run with -race to check a single node can be shared between goroutines
+func TestHiveRpcNodeConcurrentUse(t *testing.T) {
+	node, h := newTestNode(t)
+	node.handle("condenser_api.get_accounts", func(json.RawMessage) (interface{}, error) {
+		return []map[string]interface{}{{"name": "xeroc"}}, nil
+	})
+	node.handle("block_api.get_block", func(params json.RawMessage) (interface{}, error) {
+		var p getBlockQueryParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"block": map[string]interface{}{"block_id": strconv.Itoa(p.BlockNum)}}, nil
+	})
+	node.handle("block_api.get_block_range", func(params json.RawMessage) (interface{}, error) {
+		var p getBlockRangeQueryParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, err
+		}
+		var blocks []map[string]interface{}
+		for i := 0; i < p.Count; i++ {
+			blocks = append(blocks, map[string]interface{}{"block_id": strconv.Itoa(p.StartingBlockNum + i)})
+		}
+		return map[string]interface{}{"blocks": blocks}, nil
+	})
+	signer, _ := NewKeySigner("5JuMt237G3m3BaT7zH4YdoycUtbw4AEPy6DLdCrKAnFGAtXyQ1W")
+
+	const workers = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, 3*workers+4)
+	for i := 0; i < workers; i++ {
+		wg.Add(3)
+		go func(i int) {
+			defer wg.Done()
+			_, err := h.BroadcastJson([]string{}, []string{"xeroc"}, "test"+strconv.Itoa(i), "{}", signer)
+			errs <- err
+		}(i)
+		go func() {
+			defer wg.Done()
+			accounts, err := h.GetAccount([]string{"xeroc"})
+			if err == nil && (len(accounts) != 1 || accounts[0].Name != "xeroc") {
+				t.Error("Expected account xeroc, got", accounts)
+			}
+			errs <- err
+		}()
+		go func(i int) {
+			defer wg.Done()
+			block, err := h.GetBlock(i + 1)
+			if err == nil && block.BlockID != strconv.Itoa(i+1) {
+				t.Error("Expected block", i+1, "got", block.BlockID)
+			}
+			errs <- err
+		}(i)
+	}
+
+	// block fetches run with their own minimum connections and batch size
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func(start int) {
+			defer wg.Done()
+			blocks, err := h.GetBlockRange(start, 3)
+			if err != nil {
+				errs <- err
+				return
+			}
+			n := 0
+			for block := range blocks {
+				if block.BlockID != strconv.Itoa(start+n) {
+					t.Error("Expected block", start+n, "got", block.BlockID)
+				}
+				n++
+			}
+			if n != 3 {
+				t.Error("Expected 3 blocks, got", n)
+			}
+		}(100 * (i + 1))
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		blocks, err := h.StreamBlocks()
+		if err != nil {
+			errs <- err
+			return
+		}
+		// streaming starts at the head block
+		if block := <-blocks; block.BlockID != "4463677" {
+			t.Error("Expected block", 4463677, "got", block.BlockID)
+		}
+	}()
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+	if got := len(node.broadcastOps()); got != workers {
+		t.Error("Expected", workers, "broadcasts, got", got)
+	}
+}
+
+func TestRpcExecBatchKeepsOrder(t *testing.T) {
+	node, _ := newTestNode(t)
+	node.handle("block_api.get_block", func(params json.RawMessage) (interface{}, error) {
+		var p getBlockQueryParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"block": map[string]interface{}{"block_id": strconv.Itoa(p.BlockNum)}}, nil
+	})
+
+	// 7 queries in batches of 2, up to 4 in flight
+	h := NewHiveRpcWithOpts(node.url, 4, 2)
+	var params []getBlockQueryParams
+	for i := 0; i < 7; i++ {
+		params = append(params, getBlockQueryParams{BlockNum: i})
+	}
+
+	blocks, err := h.fetchBlock(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blocks) != 7 {
+		t.Fatal("Expected 7 blocks, got", len(blocks))
+	}
+	for i, block := range blocks {
+		if block.BlockID != strconv.Itoa(i) {
+			t.Error("Expected block", i, "got", block.BlockID)
+		}
+	}
+
+	node.handle("block_api.get_block", func(params json.RawMessage) (interface{}, error) {
+		return nil, errors.New("block not found")
+	})
+	var rpcErr *RpcError
+	if _, err = h.fetchBlock(params); !errors.As(err, &rpcErr) {
+		t.Error("Expected an RpcError, got", err)
+	}
+}
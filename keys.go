@@ -1,23 +1,40 @@
 package hivego
 
 import (
-	"bytes"
-	"errors"
+	"crypto/sha256"
 
-	"github.com/decred/base58"
 	"github.com/decred/dcrd/dcrec/secp256k1/v2"
-
-	//lint:ignore SA1019 ripemd160 is used for checksums of public keys and is required for compatibility with Hive
-	"golang.org/x/crypto/ripemd160"
 )
 
+// PublicKeyPrefix is no longer read by this package.
+//
+// Deprecated: the package level key functions use MainnetConfig; use the
+// NetworkConfig methods for other networks.
 var PublicKeyPrefix = "STM"
 
+// wifVersion is the version byte of WIF encoded private keys
+const wifVersion = 0x80
+
+// PasswordRoles are the roles a Hive wallet derives keys for from an
+// account's master password
+var PasswordRoles = []string{RoleOwner, RoleActive, RolePosting, RoleMemo}
+
 type KeyPair struct {
 	PrivateKey *secp256k1.PrivateKey
 	PublicKey  *secp256k1.PublicKey
 }
 
+// KeyHolder is a private key used to sign messages and encrypt memos and
+// payloads, either a *KeyPair or a *PrivateKey. Prefer a PrivateKey, which
+// can be zeroed.
+type KeyHolder interface {
+	withKey(fn func(*secp256k1.PrivateKey) error) error
+}
+
+func (kp *KeyPair) withKey(fn func(*secp256k1.PrivateKey) error) error {
+	return fn(kp.PrivateKey)
+}
+
 // Gets a KeyPair from a given WIF String
 func KeyPairFromWif(wif string) (*KeyPair, error) {
 	privKey, _, err := GphBase58CheckDecode(wif)
@@ -31,79 +48,63 @@ func KeyPairFromWif(wif string) (*KeyPair, error) {
 	return &KeyPair{prvKey, pubKey}, nil
 }
 
-// Decodes a base58 Hive public key to secp256k1 public key
-func DecodePublicKey(pubKey string) (*secp256k1.PublicKey, error) {
-	// check prefix matches
-	if pubKey[:len(PublicKeyPrefix)] != PublicKeyPrefix {
-		return nil, errors.New("invalid prefix")
-	}
-
-	// remove prefix
-	pubKey = pubKey[len(PublicKeyPrefix):]
-
-	// decode base58
-	decoded := base58.Decode(pubKey)
-
-	// get checksum
-	checksum := decoded[len(decoded)-4:]
-
-	// get public key
-	pubKeyBytes := decoded[:len(decoded)-4]
-
-	// get ripemd160 hash (checksum)
-	hasher := ripemd160.New()
-	_, err := hasher.Write(pubKeyBytes)
-	newChecksum := hasher.Sum(nil)[:4]
-
+// GenerateKeyPair creates a new key pair from crypto/rand
+func GenerateKeyPair() (*KeyPair, error) {
+	prvKey, err := secp256k1.GeneratePrivateKey()
 	if err != nil {
 		return nil, err
 	}
+	return &KeyPair{prvKey, prvKey.PubKey()}, nil
+}
+
+// KeyPairFromPassword derives the key a Hive wallet generates for one of an
+// account's roles from its master password, sha256(account + role + password)
+func KeyPairFromPassword(account string, role string, password string) *KeyPair {
+	seed := sha256.Sum256([]byte(account + role + password))
+	prvKey, pubKey := secp256k1.PrivKeyFromBytes(seed[:])
+	return &KeyPair{prvKey, pubKey}
+}
 
-	// check if checksums match
-	if !bytes.Equal(checksum, newChecksum) {
-		return nil, errors.New("checksums do not match")
+// KeysFromPassword derives the key of each of PasswordRoles, keyed by role
+func KeysFromPassword(account string, password string) map[string]*KeyPair {
+	keys := make(map[string]*KeyPair, len(PasswordRoles))
+	for _, role := range PasswordRoles {
+		keys[role] = KeyPairFromPassword(account, role, password)
 	}
+	return keys
+}
 
-	parsedKey, err := secp256k1.ParsePubKey(pubKeyBytes)
+// Wif encodes the private key in wallet import format
+func (kp *KeyPair) Wif() string {
+	return GphBase58CheckEncode(kp.PrivateKey.Serialize(), wifVersion)
+}
 
+// Decodes a base58 Hive mainnet public key to secp256k1 public key
+func DecodePublicKey(pubKey string) (*secp256k1.PublicKey, error) {
+	return MainnetConfig.DecodePublicKey(pubKey)
+}
+
+func decodePublicKey(pubKey string, prefix string) (*secp256k1.PublicKey, error) {
+	k, err := parsePublicKey(pubKey, prefix)
 	if err != nil {
 		return nil, err
 	}
-
-	return parsedKey, nil
+	return k.Key()
 }
 
 func (kp *KeyPair) GetPublicKeyString() *string {
 	return GetPublicKeyString(kp.PublicKey)
 }
 
+// GetPublicKeyString encodes pubKey with the mainnet prefix
 func GetPublicKeyString(pubKey *secp256k1.PublicKey) *string {
-	if pubKey == nil {
-		return nil
-	}
-
-	pubKeyBytes := pubKey.SerializeCompressed()
-
-	// get ripemd160 hash
-	hasher := ripemd160.New()
-	_, err := hasher.Write(pubKeyBytes)
+	return MainnetConfig.PublicKeyString(pubKey)
+}
 
-	if err != nil {
+func publicKeyString(pubKey *secp256k1.PublicKey, prefix string) *string {
+	if pubKey == nil {
 		return nil
 	}
-
-	// get checksum
-	checksum := hasher.Sum(nil)[:4]
-
-	// append checksum to public key
-
-	pubKeyBytes = append(pubKeyBytes, checksum...)
-
-	// encode to base58
-	encoded := base58.Encode(pubKeyBytes)
-
-	// add prefix
-	encoded = PublicKeyPrefix + encoded
-
+	encoded := newPublicKey(pubKey, prefix).String()
 	return &encoded
 }
@@ -2,10 +2,11 @@ package hivego_test
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"testing"
 
-	"github.com/cfoxon/hivego"
 	"github.com/decred/dcrd/dcrec/secp256k1/v2"
+	"github.com/mattrltrent/hivego"
 )
 
 func TestKeyPairFromWif(t *testing.T) {
@@ -61,3 +62,61 @@ func TestGetPublicKeyString(t *testing.T) {
 		t.Errorf("Public Key string %s does not match expected string %s", *pubKeyString, pubKeyStringExpected)
 	}
 }
+
+func TestWif(t *testing.T) {
+	wif := "5JuMt237G3m3BaT7zH4YdoycUtbw4AEPy6DLdCrKAnFGAtXyQ1W"
+	keyPair, _ := hivego.KeyPairFromWif(wif)
+	if got := keyPair.Wif(); got != wif {
+		t.Error("Expected", wif, "got", got)
+	}
+}
+
+func TestKeysFromPassword(t *testing.T) {
+	keys := hivego.KeysFromPassword("xeroc", "P5KZKeVs1zj9yNmVDSpFmwNqiC1uyE9Wn5g5V7kcm2Kr6Cr8jHQL")
+	if len(keys) != len(hivego.PasswordRoles) {
+		t.Fatal("Expected a key per role, got", len(keys))
+	}
+
+	seed := sha256.Sum256([]byte("xeroc" + "active" + "P5KZKeVs1zj9yNmVDSpFmwNqiC1uyE9Wn5g5V7kcm2Kr6Cr8jHQL"))
+	if !bytes.Equal(keys[hivego.RoleActive].PrivateKey.Serialize(), seed[:]) {
+		t.Error("Expected the active key to be sha256(account + role + password)")
+	}
+	if keys[hivego.RoleActive].PublicKey.IsEqual(keys[hivego.RolePosting].PublicKey) {
+		t.Error("Expected each role to get its own key")
+	}
+
+	decoded, err := hivego.KeyPairFromWif(keys[hivego.RoleActive].Wif())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.PublicKey.IsEqual(keys[hivego.RoleActive].PublicKey) {
+		t.Error("Expected the WIF to round trip")
+	}
+}
+
+func TestKeyPairFromPassword(t *testing.T) {
+	// the active key dsteem's PrivateKey.fromLogin("foo", "barman") derives
+	const expected = "STM87F7tN56tAUL2C6J9Gzi9HzgNpZdi6M2cLQo7TjDU5v178QsYA"
+	if got := *hivego.KeyPairFromPassword("foo", hivego.RoleActive, "barman").GetPublicKeyString(); got != expected {
+		t.Error("Expected", expected, "got", got)
+	}
+}
+
+func TestGenerateKeyPair(t *testing.T) {
+	a, err := hivego.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := hivego.GenerateKeyPair()
+	if a.PublicKey.IsEqual(b.PublicKey) {
+		t.Error("Expected distinct keys")
+	}
+
+	decoded, err := hivego.KeyPairFromWif(a.Wif())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.PublicKey.IsEqual(a.PublicKey) {
+		t.Error("Expected the WIF to round trip")
+	}
+}
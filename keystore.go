@@ -0,0 +1,496 @@
+package hivego
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v2"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// version 1 encrypted a JSON map of public keys to WIFs; version 2
+	// encrypts the 32 byte secrets back to back, so no key passes through an
+	// unwipeable string
+	keystoreVersion = 2
+	scryptN         = 1 << 15
+	scryptR         = 8
+	scryptP         = 1
+	scryptKeyLen    = 32
+)
+
+var (
+	ErrKeystoreLocked     = errors.New("keystore is locked")
+	ErrKeystorePassphrase = errors.New("wrong keystore passphrase or corrupted keystore")
+)
+
+// KeystoreEntry is the public part of a key held by a Keystore
+type KeystoreEntry struct {
+	PublicKey string `json:"public_key"`
+	Account   string `json:"account"`
+	Role      string `json:"role"`
+}
+
+type keystoreKdf struct {
+	Name string `json:"name"`
+	Salt []byte `json:"salt"`
+	N    int    `json:"n"`
+	R    int    `json:"r"`
+	P    int    `json:"p"`
+}
+
+type keystoreFile struct {
+	Version    int             `json:"version"`
+	Kdf        keystoreKdf     `json:"kdf"`
+	Entries    []KeystoreEntry `json:"entries"`
+	Nonce      []byte          `json:"nonce"`
+	Ciphertext []byte          `json:"ciphertext"`
+}
+
+// Keystore holds private keys encrypted with a passphrase, indexed by public key and
+// by the account and role each key is for. The index can be read while the
+// keystore is locked; the keys themselves only while it is unlocked. It is
+// safe for concurrent use.
+//
+// On disk the keys are encrypted with AES-256-GCM under a key derived from
+// the passphrase with scrypt, and the index is authenticated along with them.
+type Keystore struct {
+	mu      sync.Mutex
+	kdf     keystoreKdf
+	entries []KeystoreEntry
+
+	// set while unlocked
+	aead cipher.AEAD
+	keys map[string]*PrivateKey
+
+	// the encrypted keystore, sealed again on every change
+	file *keystoreFile
+}
+
+// NewKeystore returns an empty, unlocked keystore protected by passphrase
+func NewKeystore(passphrase string) (*Keystore, error) {
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	k := &Keystore{kdf: keystoreKdf{Name: "scrypt", Salt: salt, N: scryptN, R: scryptR, P: scryptP}}
+
+	aead, err := k.deriveAead(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	k.aead = aead
+	k.keys = map[string]*PrivateKey{}
+	return k, k.seal()
+}
+
+// LoadKeystore reads a keystore saved with Save. It is returned locked.
+func LoadKeystore(path string) (*Keystore, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file keystoreFile
+	err = json.Unmarshal(data, &file)
+	if err != nil {
+		return nil, err
+	}
+	if file.Version != 1 && file.Version != keystoreVersion {
+		return nil, fmt.Errorf("unsupported keystore version %d", file.Version)
+	}
+	if file.Kdf.Name != "scrypt" {
+		return nil, errors.New("unsupported keystore kdf: " + file.Kdf.Name)
+	}
+	return &Keystore{kdf: file.Kdf, entries: file.Entries, file: &file}, nil
+}
+
+func (k *Keystore) deriveAead(passphrase string) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), k.kdf.Salt, k.kdf.N, k.kdf.R, k.kdf.P, scryptKeyLen)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Unlock decrypts the keystore's keys with passphrase. The passphrase is
+// checked even when the keystore is already unlocked.
+func (k *Keystore) Unlock(passphrase string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	aead, err := k.deriveAead(passphrase)
+	if err != nil {
+		return err
+	}
+	ad, err := json.Marshal(k.file.Entries)
+	if err != nil {
+		return err
+	}
+	plain, err := aead.Open(nil, k.file.Nonce, k.file.Ciphertext, ad)
+	if err != nil {
+		return ErrKeystorePassphrase
+	}
+	defer zero(plain)
+	if k.keys != nil {
+		return nil
+	}
+
+	var keys map[string]*PrivateKey
+	if k.file.Version == 1 {
+		keys, err = decodeKeystoreWifs(plain)
+	} else {
+		keys, err = decodeKeystoreSecrets(plain)
+	}
+	if err != nil {
+		return err
+	}
+
+	k.aead = aead
+	k.keys = keys
+	return nil
+}
+
+// Lock zeroes the decrypted keys and forgets the passphrase derived key.
+// Signers from the keystore fail with ErrKeystoreLocked until it is unlocked
+// again.
+func (k *Keystore) Lock() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	destroyKeys(k.keys)
+	k.aead = nil
+	k.keys = nil
+}
+
+func (k *Keystore) Locked() bool {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.keys == nil
+}
+
+// Entries lists the keys in the keystore, which is possible while locked
+func (k *Keystore) Entries() []KeystoreEntry {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return append([]KeystoreEntry{}, k.entries...)
+}
+
+// Add stores wif as account's key for role (owner, active, posting or memo)
+func (k *Keystore) Add(wif string, account string, role string) error {
+	switch role {
+	case RoleOwner, RoleActive, RolePosting, RoleMemo:
+	default:
+		return errors.New("unknown role: " + role)
+	}
+	key, err := PrivateKeyFromWif(wif)
+	if err != nil {
+		return err
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.keys == nil {
+		key.Destroy()
+		return ErrKeystoreLocked
+	}
+
+	entry := KeystoreEntry{PublicKey: *GetPublicKeyString(key.PublicKey()), Account: account, Role: role}
+	for _, e := range k.entries {
+		if e == entry {
+			key.Destroy()
+			return nil
+		}
+	}
+	k.entries = append(k.entries, entry)
+	if existing, ok := k.keys[entry.PublicKey]; ok {
+		key.Destroy()
+		key = existing
+	}
+	k.keys[entry.PublicKey] = key
+	return k.seal()
+}
+
+// Remove deletes the key with the given public key
+func (k *Keystore) Remove(pubKey string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.keys == nil {
+		return ErrKeystoreLocked
+	}
+
+	var entries []KeystoreEntry
+	for _, e := range k.entries {
+		if e.PublicKey != pubKey {
+			entries = append(entries, e)
+		}
+	}
+	k.entries = entries
+	if key, ok := k.keys[pubKey]; ok {
+		key.Destroy()
+		delete(k.keys, pubKey)
+	}
+	return k.seal()
+}
+
+// Save writes the encrypted keystore to path, readable only by the current
+// user. A locked keystore can be saved too. The file is written to a
+// temporary file beside path and renamed over it, so a crash never leaves a
+// truncated keystore behind.
+func (k *Keystore) Save(path string) error {
+	k.mu.Lock()
+	data, err := json.MarshalIndent(k.file, "", "  ")
+	k.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	// CreateTemp creates the file with mode 0600
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	renamed := false
+	defer func() {
+		if !renamed {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err = tmp.Write(data); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+	renamed = true
+	return nil
+}
+
+// seal encrypts the keys of an unlocked keystore into k.file
+func (k *Keystore) seal() error {
+	plain := make([]byte, 0, 32*len(k.keys))
+	defer func() { zero(plain) }()
+	for _, key := range k.keys {
+		var err error
+		plain, err = key.appendSecret(plain)
+		if err != nil {
+			return err
+		}
+	}
+	entries := append([]KeystoreEntry{}, k.entries...)
+	ad, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, k.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	k.file = &keystoreFile{
+		Version:    keystoreVersion,
+		Kdf:        k.kdf,
+		Entries:    entries,
+		Nonce:      nonce,
+		Ciphertext: k.aead.Seal(nil, nonce, plain, ad),
+	}
+	return nil
+}
+
+// decodeKeystoreSecrets reads the keys of a version 2 keystore, 32 byte
+// secrets back to back
+func decodeKeystoreSecrets(plain []byte) (map[string]*PrivateKey, error) {
+	if len(plain)%32 != 0 {
+		return nil, ErrKeystorePassphrase
+	}
+	keys := make(map[string]*PrivateKey, len(plain)/32)
+	for i := 0; i < len(plain); i += 32 {
+		key, err := PrivateKeyFromBytes(plain[i : i+32])
+		if err != nil {
+			destroyKeys(keys)
+			return nil, err
+		}
+		keys[*GetPublicKeyString(key.PublicKey())] = key
+	}
+	return keys, nil
+}
+
+// decodeKeystoreWifs reads the keys of a version 1 keystore, a JSON map of
+// public keys to WIFs. The WIF strings cannot be wiped; saving the keystore
+// again rewrites it as version 2.
+func decodeKeystoreWifs(plain []byte) (map[string]*PrivateKey, error) {
+	var wifs map[string]string
+	if err := json.Unmarshal(plain, &wifs); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]*PrivateKey, len(wifs))
+	for pubKey, wif := range wifs {
+		key, err := PrivateKeyFromWif(wif)
+		if err != nil {
+			destroyKeys(keys)
+			return nil, err
+		}
+		keys[pubKey] = key
+	}
+	return keys, nil
+}
+
+func destroyKeys(keys map[string]*PrivateKey) {
+	for _, key := range keys {
+		key.Destroy()
+	}
+}
+
+// Signer returns a Signer using account's key for role. A key of a higher
+// role is used when the keystore has none for role, since the chain accepts
+// an active key for posting operations and an owner key for either.
+func (k *Keystore) Signer(account string, role string) (Signer, error) {
+	return k.signerFor([]accountRole{{account, role}})
+}
+
+// SignerFor returns a Signer with the keys needed to authorize ops
+func (k *Keystore) SignerFor(ops []HiveOperation) (Signer, error) {
+	var required []accountRole
+	for _, op := range ops {
+		auths, err := requiredAuthorities(op)
+		if err != nil {
+			return nil, err
+		}
+		required = append(required, auths...)
+	}
+	return k.signerFor(required)
+}
+
+type accountRole struct {
+	account string
+	role    string
+}
+
+// satisfyingRoles lists the roles whose keys can sign for role, preferring
+// the least powerful
+var satisfyingRoles = map[string][]string{
+	RolePosting: {RolePosting, RoleActive, RoleOwner},
+	RoleActive:  {RoleActive, RoleOwner},
+	RoleOwner:   {RoleOwner},
+}
+
+func (k *Keystore) signerFor(required []accountRole) (Signer, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	s := &keystoreSigner{keystore: k}
+	seen := map[string]bool{}
+	for _, req := range required {
+		roles, ok := satisfyingRoles[req.role]
+		if !ok {
+			return nil, errors.New("unknown authority role: " + req.role)
+		}
+
+		found := false
+		for _, role := range roles {
+			for _, e := range k.entries {
+				if e.Account != req.account || e.Role != role {
+					continue
+				}
+				found = true
+				if !seen[e.PublicKey] {
+					seen[e.PublicKey] = true
+					s.pubKeys = append(s.pubKeys, e.PublicKey)
+				}
+				break
+			}
+			if found {
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("keystore has no key for %s's %s authority", req.account, req.role)
+		}
+	}
+	return s, nil
+}
+
+// keystoreSigner signs with keys looked up in its keystore at signing time,
+// so it stops working once the keystore is locked
+type keystoreSigner struct {
+	keystore *Keystore
+	pubKeys  []string
+}
+
+func (s *keystoreSigner) PublicKeys() []*secp256k1.PublicKey {
+	var keys []*secp256k1.PublicKey
+	for _, pubKey := range s.pubKeys {
+		key, err := DecodePublicKey(pubKey)
+		if err == nil {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func (s *keystoreSigner) SignDigest(digest []byte) ([][]byte, error) {
+	s.keystore.mu.Lock()
+	defer s.keystore.mu.Unlock()
+	if s.keystore.keys == nil {
+		return nil, ErrKeystoreLocked
+	}
+
+	var sigs [][]byte
+	for _, pubKey := range s.pubKeys {
+		key, ok := s.keystore.keys[pubKey]
+		if !ok {
+			return nil, errors.New("key removed from keystore: " + pubKey)
+		}
+		sig, err := key.Sign(digest)
+		if err != nil {
+			return nil, err
+		}
+		sigs = append(sigs, sig)
+	}
+	return sigs, nil
+}
+
+// requiredAuthorities lists the account authorities that must sign op
+func requiredAuthorities(op HiveOperation) ([]accountRole, error) {
+	switch op := op.(type) {
+	case voteOperation:
+		return []accountRole{{op.Voter, RolePosting}}, nil
+	case customJsonOperation:
+		var auths []accountRole
+		for _, account := range op.RequiredAuths {
+			auths = append(auths, accountRole{account, RoleActive})
+		}
+		for _, account := range op.RequiredPostingAuths {
+			auths = append(auths, accountRole{account, RolePosting})
+		}
+		return auths, nil
+	case claimRewardOperation:
+		return []accountRole{{op.Account, RolePosting}}, nil
+	case transferOperation:
+		return []accountRole{{op.From, RoleActive}}, nil
+	case accountUpdateOperation:
+		if op.Owner != nil {
+			return []accountRole{{op.Account, RoleOwner}}, nil
+		}
+		return []accountRole{{op.Account, RoleActive}}, nil
+	}
+	return nil, errors.New("cannot determine the authorities required by operation " + op.OpName())
+}
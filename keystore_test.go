@@ -0,0 +1,188 @@
+package hivego
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestKeystore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wallet.json")
+	posting := KeyPairFromPassword("xeroc", RolePosting, "p")
+	active := KeyPairFromPassword("xeroc", RoleActive, "p")
+
+	ks, err := NewKeystore("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ks.Add(posting.Wif(), "xeroc", RolePosting); err != nil {
+		t.Fatal(err)
+	}
+	if err = ks.Add(active.Wif(), "xeroc", RoleActive); err != nil {
+		t.Fatal(err)
+	}
+	if err = ks.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), posting.Wif()) {
+		t.Fatal("Expected the WIFs to be encrypted")
+	}
+
+	ks, err = LoadKeystore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ks.Locked() || len(ks.Entries()) != 2 {
+		t.Fatal("Expected a locked keystore listing 2 keys, got", ks.Entries())
+	}
+
+	signer, err := ks.SignerFor([]HiveOperation{getTestVoteOp()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = signer.SignDigest(make([]byte, 32)); !errors.Is(err, ErrKeystoreLocked) {
+		t.Error("Expected", ErrKeystoreLocked, "got", err)
+	}
+
+	if err = ks.Unlock("wrong"); !errors.Is(err, ErrKeystorePassphrase) {
+		t.Error("Expected", ErrKeystorePassphrase, "got", err)
+	}
+	if err = ks.Unlock("correct horse"); err != nil {
+		t.Fatal(err)
+	}
+	// unlocking again still checks the passphrase
+	if err = ks.Unlock("wrong"); !errors.Is(err, ErrKeystorePassphrase) || ks.Locked() {
+		t.Error("Expected", ErrKeystorePassphrase, "and the keystore to stay unlocked, got", err)
+	}
+	if err = ks.Unlock("correct horse"); err != nil {
+		t.Error("Expected the passphrase to be accepted again, got", err)
+	}
+	if keys := signer.PublicKeys(); len(keys) != 1 || !keys[0].IsEqual(posting.PublicKey) {
+		t.Error("Expected the vote to need xeroc's posting key")
+	}
+	if _, err = signer.SignDigest(make([]byte, 32)); err != nil {
+		t.Error(err)
+	}
+
+	ks.Lock()
+	if _, err = signer.SignDigest(make([]byte, 32)); !errors.Is(err, ErrKeystoreLocked) {
+		t.Error("Expected", ErrKeystoreLocked, "after locking, got", err)
+	}
+}
+
+func TestKeystoreSignerFor(t *testing.T) {
+	active := KeyPairFromPassword("xeroc", RoleActive, "p")
+	ks, _ := NewKeystore("pass")
+	_ = ks.Add(active.Wif(), "xeroc", RoleActive)
+
+	transfer := transferOperation{"xeroc", "piston", "1.000 HIVE", "", "transfer", nil}
+	for _, ops := range [][]HiveOperation{{transfer}, {getTestVoteOp()}} {
+		// the vote falls back to the active key as there is no posting key
+		signer, err := ks.SignerFor(ops)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if keys := signer.PublicKeys(); len(keys) != 1 || !keys[0].IsEqual(active.PublicKey) {
+			t.Error("Expected xeroc's active key")
+		}
+	}
+
+	if _, err := ks.Signer("xeroc", RoleOwner); err == nil {
+		t.Error("Expected no key for xeroc's owner authority")
+	}
+	if _, err := ks.Signer("piston", RolePosting); err == nil {
+		t.Error("Expected no key for piston")
+	}
+}
+
+func TestKeystoreVersion1(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wallet.json")
+	posting := KeyPairFromPassword("xeroc", RolePosting, "p")
+	pubKey := *posting.GetPublicKeyString()
+
+	// a version 1 keystore encrypts a JSON map of public keys to WIFs
+	ks, _ := NewKeystore("pass")
+	ks.entries = []KeystoreEntry{{pubKey, "xeroc", RolePosting}}
+	plain, _ := json.Marshal(map[string]string{pubKey: posting.Wif()})
+	ad, _ := json.Marshal(ks.entries)
+	nonce := make([]byte, ks.aead.NonceSize())
+	ks.file = &keystoreFile{Version: 1, Kdf: ks.kdf, Entries: ks.entries, Nonce: nonce, Ciphertext: ks.aead.Seal(nil, nonce, plain, ad)}
+	if err := ks.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	ks, err := LoadKeystore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ks.Unlock("pass"); err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ks.Signer("xeroc", RolePosting)
+	if err != nil || !signer.PublicKeys()[0].IsEqual(posting.PublicKey) {
+		t.Error("Expected xeroc's posting key, got", err)
+	}
+}
+
+func TestKeystoreSaveReplacesFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "wallet.json")
+	_ = os.WriteFile(path, []byte("old"), 0644)
+
+	ks, _ := NewKeystore("pass")
+	if err := ks.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadKeystore(path); err != nil {
+		t.Error("Expected the saved keystore to load, got", err)
+	}
+
+	if info, _ := os.Stat(path); runtime.GOOS != "windows" && info.Mode().Perm() != 0600 {
+		t.Error("Expected mode 0600, got", info.Mode().Perm())
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Error("Expected only the keystore to be left, got", len(entries), "files")
+	}
+}
+
+func TestKeystoreRejectsTamperedIndex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wallet.json")
+	ks, _ := NewKeystore("pass")
+	_ = ks.Add(KeyPairFromPassword("xeroc", RoleActive, "p").Wif(), "xeroc", RoleActive)
+	_ = ks.Save(path)
+
+	data, _ := os.ReadFile(path)
+	_ = os.WriteFile(path, []byte(strings.Replace(string(data), `"xeroc"`, `"mallory"`, 1)), 0600)
+
+	ks, err := LoadKeystore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ks.Unlock("pass"); !errors.Is(err, ErrKeystorePassphrase) {
+		t.Error("Expected", ErrKeystorePassphrase, "got", err)
+	}
+}
+
+func TestBroadcastWithKeystore(t *testing.T) {
+	node, h := newTestNode(t)
+	ks, _ := NewKeystore("pass")
+	_ = ks.Add("5JuMt237G3m3BaT7zH4YdoycUtbw4AEPy6DLdCrKAnFGAtXyQ1W", "xeroc", RolePosting)
+
+	ops := []HiveOperation{getTestVoteOp()}
+	signer, err := ks.SignerFor(ops)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = h.Broadcast(ops, signer); err != nil {
+		t.Fatal(err)
+	}
+	if len(node.broadcastOps()) != 1 {
+		t.Error("Expected one broadcast")
+	}
+}
@@ -0,0 +1,232 @@
+package hivego
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"strings"
+
+	"github.com/decred/base58"
+	"github.com/decred/dcrd/dcrec/secp256k1/v2"
+)
+
+// ErrMemoChecksum is returned by DecryptMemo when the memo's checksum does not
+// match the key derived to decrypt it, e.g. because it was tampered with
+var ErrMemoChecksum = errors.New("memo checksum mismatch")
+
+// EncryptMemo encrypts a memo starting with "#" from the memo key from to the
+// memo key to, the way Hive wallets do. Other memos are returned unchanged.
+func EncryptMemo(memo string, from KeyHolder, to *secp256k1.PublicKey) (string, error) {
+	if !strings.HasPrefix(memo, "#") {
+		return memo, nil
+	}
+
+	nonceB := make([]byte, 8)
+	if _, err := rand.Read(nonceB); err != nil {
+		return "", err
+	}
+	return encryptMemo(memo, from, to, binary.LittleEndian.Uint64(nonceB))
+}
+
+func encryptMemo(memo string, from KeyHolder, to *secp256k1.PublicKey, nonce uint64) (string, error) {
+	var plain bytes.Buffer
+	appendVString(memo[1:], &plain)
+
+	var key, iv []byte
+	var check uint32
+	var fromPub *secp256k1.PublicKey
+	err := from.withKey(func(priv *secp256k1.PrivateKey) error {
+		key, iv, check = memoKeys(priv, to, nonce)
+		fromPub = priv.PubKey()
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	defer zero(key)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+	padded := pkcs7Pad(plain.Bytes(), aes.BlockSize)
+	encrypted := make([]byte, len(padded))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(encrypted, padded)
+
+	var buf bytes.Buffer
+	buf.Write(fromPub.SerializeCompressed())
+	buf.Write(to.SerializeCompressed())
+	_ = binary.Write(&buf, binary.LittleEndian, nonce)
+	_ = binary.Write(&buf, binary.LittleEndian, check)
+	_ = WriteUvarint(&buf, uint64(len(encrypted)))
+	buf.Write(encrypted)
+
+	return "#" + base58.Encode(buf.Bytes()), nil
+}
+
+// DecryptMemo decrypts a memo starting with "#" with the memo key of either
+// its sender or its recipient, returning it with its "#" prefix. Other memos
+// are returned unchanged.
+func DecryptMemo(memo string, key KeyHolder) (string, error) {
+	if !strings.HasPrefix(memo, "#") {
+		return memo, nil
+	}
+
+	from, to, nonce, check, encrypted, err := parseEncryptedMemo(memo)
+	if err != nil {
+		return "", err
+	}
+
+	var aesKey, iv []byte
+	var expected uint32
+	err = key.withKey(func(priv *secp256k1.PrivateKey) error {
+		var other *secp256k1.PublicKey
+		switch pub := priv.PubKey(); {
+		case pub.IsEqual(from):
+			other = to
+		case pub.IsEqual(to):
+			other = from
+		default:
+			return errors.New("memo was not encrypted for this key")
+		}
+		aesKey, iv, expected = memoKeys(priv, other, nonce)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	defer zero(aesKey)
+	if check != expected {
+		return "", ErrMemoChecksum
+	}
+	block, err := aes.NewCipher(aesKey)
+	if err != nil {
+		return "", err
+	}
+	plain := make([]byte, len(encrypted))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plain, encrypted)
+	plain, err = pkcs7Unpad(plain, aes.BlockSize)
+	if err != nil {
+		return "", err
+	}
+
+	// the message is a varint prefixed string, though some wallets wrote it raw
+	pr := bytes.NewReader(plain)
+	if n, err := binary.ReadUvarint(pr); err == nil && n == uint64(pr.Len()) {
+		plain = plain[len(plain)-int(n):]
+	}
+	return "#" + string(plain), nil
+}
+
+// parseEncryptedMemo splits an encrypted memo into its envelope and
+// ciphertext
+func parseEncryptedMemo(memo string) (from, to *secp256k1.PublicKey, nonce uint64, check uint32, encrypted []byte, err error) {
+	data := base58.Decode(strings.TrimPrefix(memo, "#"))
+	if len(data) < 33+33+8+4 {
+		return nil, nil, 0, 0, nil, errors.New("invalid encrypted memo")
+	}
+	from, err = secp256k1.ParsePubKey(data[:33])
+	if err != nil {
+		return nil, nil, 0, 0, nil, err
+	}
+	to, err = secp256k1.ParsePubKey(data[33:66])
+	if err != nil {
+		return nil, nil, 0, 0, nil, err
+	}
+	nonce = binary.LittleEndian.Uint64(data[66:74])
+	check = binary.LittleEndian.Uint32(data[74:78])
+	r := bytes.NewReader(data[78:])
+	length, err := binary.ReadUvarint(r)
+	if err != nil || length != uint64(r.Len()) || length == 0 || length%aes.BlockSize != 0 {
+		return nil, nil, 0, 0, nil, errors.New("invalid encrypted memo")
+	}
+	return from, to, nonce, check, data[len(data)-int(length):], nil
+}
+
+// isPlaintextPrivateMemo reports whether memo is marked private with "#" but
+// not yet encrypted, so it must not go on chain as it is
+func isPlaintextPrivateMemo(memo string) bool {
+	if !strings.HasPrefix(memo, "#") {
+		return false
+	}
+	_, _, _, _, _, err := parseEncryptedMemo(memo)
+	return err != nil
+}
+
+// memoKeySource is implemented by signers that can also hand out the private
+// key of a memo public key they hold, so Transfer can encrypt memos with it
+type memoKeySource interface {
+	memoKey(pub *secp256k1.PublicKey) KeyHolder
+}
+
+func (k *PrivateKey) memoKey(pub *secp256k1.PublicKey) KeyHolder {
+	if k.pub.IsEqual(pub) {
+		return k
+	}
+	return nil
+}
+
+func (s *KeySigner) memoKey(pub *secp256k1.PublicKey) KeyHolder {
+	for _, key := range s.keys {
+		if key.pub.IsEqual(pub) {
+			return key
+		}
+	}
+	return nil
+}
+
+func (s *keystoreSigner) memoKey(pub *secp256k1.PublicKey) KeyHolder {
+	s.keystore.mu.Lock()
+	defer s.keystore.mu.Unlock()
+	if key, ok := s.keystore.keys[*GetPublicKeyString(pub)]; ok {
+		return key
+	}
+	return nil
+}
+
+// memoKeys derives the AES key, IV and checksum of a memo from the shared
+// secret of the two memo keys and the memo's nonce
+func memoKeys(priv *secp256k1.PrivateKey, pub *secp256k1.PublicKey, nonce uint64) ([]byte, []byte, uint32) {
+	secret := sharedSecret(priv, pub)
+
+	seed := make([]byte, 8, 8+len(secret))
+	binary.LittleEndian.PutUint64(seed, nonce)
+	seed = append(seed, secret[:]...)
+	encryptionKey := sha512.Sum512(seed)
+
+	check := sha256.Sum256(encryptionKey[:])
+	return encryptionKey[:32], encryptionKey[32:48], binary.LittleEndian.Uint32(check[:4])
+}
+
+// sharedSecret is the sha512 of the x coordinate of the ECDH shared point
+func sharedSecret(priv *secp256k1.PrivateKey, pub *secp256k1.PublicKey) [64]byte {
+	x := make([]byte, 32)
+	shared := secp256k1.GenerateSharedSecret(priv, pub)
+	copy(x[32-len(shared):], shared)
+	return sha512.Sum512(x)
+}
+
+func pkcs7Pad(data []byte, blockSize int) []byte {
+	n := blockSize - len(data)%blockSize
+	return append(data, bytes.Repeat([]byte{byte(n)}, n)...)
+}
+
+func pkcs7Unpad(data []byte, blockSize int) ([]byte, error) {
+	if len(data) == 0 || len(data)%blockSize != 0 {
+		return nil, errors.New("invalid padding")
+	}
+	n := int(data[len(data)-1])
+	if n == 0 || n > blockSize || n > len(data) {
+		return nil, errors.New("invalid padding")
+	}
+	for _, b := range data[len(data)-n:] {
+		if int(b) != n {
+			return nil, errors.New("invalid padding")
+		}
+	}
+	return data[:len(data)-n], nil
+}
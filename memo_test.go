@@ -0,0 +1,150 @@
+package hivego
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/decred/base58"
+	"github.com/decred/dcrd/dcrec/secp256k1/v2"
+)
+
+func TestEncryptMemo(t *testing.T) {
+	// vector from steem-js, with the key sha256("") sending to itself
+	seed := sha256.Sum256([]byte(""))
+	prvKey, pubKey := secp256k1.PrivKeyFromBytes(seed[:])
+	key := &KeyPair{prvKey, pubKey}
+	expected := "#HU6pdQ4Hh8cFrDVooekRPVZu4BdrhAe9RxrWrei2CwfAApAPdM4PT5mSV9cV3tTuWKotYQF6suyM4JHFBZz4pcwyezPzuZ2na7uwhRcLqFoqCam1VU3eCLjVNqcgUNbH3"
+
+	got, err := encryptMemo("#爱", key, pubKey, 1462976530069648)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != expected {
+		t.Error("Expected", expected, "got", got)
+	}
+
+	plain, err := DecryptMemo(expected, key)
+	if err != nil || plain != "#爱" {
+		t.Error("Expected #爱, got", plain, err)
+	}
+
+	// a PrivateKey gives the same memo
+	privKey, _ := PrivateKeyFromBytes(seed[:])
+	if got, err = encryptMemo("#爱", privKey, pubKey, 1462976530069648); err != nil || got != expected {
+		t.Error("Expected", expected, "from a PrivateKey, got", got, err)
+	}
+	if plain, err = DecryptMemo(expected, privKey); err != nil || plain != "#爱" {
+		t.Error("Expected #爱 from a PrivateKey, got", plain, err)
+	}
+	privKey.Destroy()
+	if _, err = DecryptMemo(expected, privKey); !errors.Is(err, ErrKeyDestroyed) {
+		t.Error("Expected", ErrKeyDestroyed, "got", err)
+	}
+
+	if got, _ = EncryptMemo("not secret", key, pubKey); got != "not secret" {
+		t.Error("Expected a memo without # to be left as is, got", got)
+	}
+}
+
+func TestDecryptMemo(t *testing.T) {
+	sender := KeyPairFromPassword("alice", RoleMemo, "a")
+	recipient := KeyPairFromPassword("bob", RoleMemo, "b")
+	other := KeyPairFromPassword("eve", RoleMemo, "e")
+
+	encrypted, err := EncryptMemo("#deposit 1234", sender, recipient.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []*KeyPair{sender, recipient} {
+		plain, err := DecryptMemo(encrypted, key)
+		if err != nil || plain != "#deposit 1234" {
+			t.Error("Expected #deposit 1234, got", plain, err)
+		}
+	}
+
+	if _, err = DecryptMemo(encrypted, other); err == nil {
+		t.Error("Expected a third key to be rejected")
+	}
+
+	// a changed nonce no longer matches the checksum
+	data := base58.Decode(encrypted[1:])
+	data[66] ^= 1
+	if _, err = DecryptMemo("#"+base58.Encode(data), recipient); !errors.Is(err, ErrMemoChecksum) {
+		t.Error("Expected", ErrMemoChecksum, "got", err)
+	}
+}
+
+func TestTransferEncrypted(t *testing.T) {
+	node, h := newTestNode(t)
+	sender := KeyPairFromPassword("xeroc", RoleMemo, "a")
+	recipient := KeyPairFromPassword("piston", RoleMemo, "b")
+	node.handle("condenser_api.get_accounts", func(json.RawMessage) (interface{}, error) {
+		return []map[string]interface{}{{"name": "piston", "memo_key": *recipient.GetPublicKeyString()}}, nil
+	})
+	signer, _ := NewKeySigner("5JuMt237G3m3BaT7zH4YdoycUtbw4AEPy6DLdCrKAnFGAtXyQ1W")
+
+	_, err := h.TransferEncrypted("xeroc", "piston", "1.000 HIVE", "#deposit 1234", sender, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ops := node.broadcastOps()
+	if len(ops) != 1 {
+		t.Fatal("Expected one broadcast, got", len(ops))
+	}
+	memo := ops[0][0].([]interface{})[1].(map[string]interface{})["memo"].(string)
+	if memo == "#deposit 1234" {
+		t.Fatal("Expected the memo to be encrypted")
+	}
+	plain, err := DecryptMemo(memo, recipient)
+	if err != nil || plain != "#deposit 1234" {
+		t.Error("Expected #deposit 1234, got", plain, err)
+	}
+}
+
+func TestTransferEncryptsPrivateMemo(t *testing.T) {
+	node, h := newTestNode(t)
+	sender := KeyPairFromPassword("xeroc", RoleMemo, "a")
+	recipient := KeyPairFromPassword("piston", RoleMemo, "b")
+	memoKeys := map[string]string{"xeroc": *sender.GetPublicKeyString(), "piston": *recipient.GetPublicKeyString()}
+	node.handle("condenser_api.get_accounts", func(params json.RawMessage) (interface{}, error) {
+		var names [][]string
+		if err := json.Unmarshal(params, &names); err != nil {
+			return nil, err
+		}
+		return []map[string]interface{}{{"name": names[0][0], "memo_key": memoKeys[names[0][0]]}}, nil
+	})
+	activeWif := "5JuMt237G3m3BaT7zH4YdoycUtbw4AEPy6DLdCrKAnFGAtXyQ1W"
+
+	// without the sender's memo key the memo is refused, never sent as it is
+	signer, _ := NewKeySigner(activeWif)
+	if _, err := h.Transfer("xeroc", "piston", "1.000 HIVE", "#deposit 1234", signer); !errors.Is(err, ErrMemoKeyUnavailable) {
+		t.Error("Expected", ErrMemoKeyUnavailable, "got", err)
+	}
+	if len(node.broadcastOps()) != 0 {
+		t.Fatal("Expected nothing to be broadcast")
+	}
+
+	signer, _ = NewKeySigner(activeWif, sender.Wif())
+	if _, err := h.Transfer("xeroc", "piston", "1.000 HIVE", "#deposit 1234", signer); err != nil {
+		t.Fatal(err)
+	}
+	ops := node.broadcastOps()
+	if len(ops) != 1 {
+		t.Fatal("Expected one broadcast, got", len(ops))
+	}
+	memo := ops[0][0].([]interface{})[1].(map[string]interface{})["memo"].(string)
+	plain, err := DecryptMemo(memo, recipient)
+	if err != nil || plain != "#deposit 1234" {
+		t.Error("Expected #deposit 1234, got", plain, err)
+	}
+
+	// a plaintext private memo never passes validation
+	op := transferOperation{"xeroc", "piston", "1.000 HIVE", "#deposit 1234", "transfer", nil}
+	var vErr *ValidationError
+	if err = op.Validate(); !errors.As(err, &vErr) || vErr.Field != "memo" {
+		t.Error("Expected memo validation error, got", err)
+	}
+}
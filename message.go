@@ -0,0 +1,58 @@
+package hivego
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v2"
+)
+
+// SignMessage signs sha256(message) with key the way Hive Keychain's
+// signBuffer does, returning the hex encoded compact signature
+func SignMessage(message []byte, key KeyHolder) (string, error) {
+	digest := sha256.Sum256(message)
+	var sig []byte
+	err := key.withKey(func(priv *secp256k1.PrivateKey) error {
+		var err error
+		sig, err = signCanonical(priv, digest[:])
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(sig), nil
+}
+
+// RecoverMessageKey recovers the public key behind a SignMessage signature
+func RecoverMessageKey(message []byte, signature string) (*secp256k1.PublicKey, error) {
+	sig, err := hex.DecodeString(signature)
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256(message)
+	return RecoverPublicKey(digest[:], sig)
+}
+
+// VerifyMessage checks that signature is pubKey's signature of message
+func VerifyMessage(message []byte, signature string, pubKey *secp256k1.PublicKey) error {
+	recovered, err := RecoverMessageKey(message, signature)
+	if err != nil {
+		return err
+	}
+	if !recovered.IsEqual(pubKey) {
+		return errors.New("message was not signed by the expected key")
+	}
+	return nil
+}
+
+// VerifyAccountMessage checks that message was signed by a key meeting the
+// threshold of account's owner, active or posting authority on chain, e.g. to
+// answer a login challenge
+func (h *HiveRpcNode) VerifyAccountMessage(account string, role string, message []byte, signature string) error {
+	pubKey, err := RecoverMessageKey(message, signature)
+	if err != nil {
+		return err
+	}
+	return h.checkAuthority(account, role, map[string]bool{*h.network().PublicKeyString(pubKey): true})
+}
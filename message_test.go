@@ -0,0 +1,61 @@
+package hivego
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSignMessage(t *testing.T) {
+	keyPair, _ := KeyPairFromWif("5JuMt237G3m3BaT7zH4YdoycUtbw4AEPy6DLdCrKAnFGAtXyQ1W")
+	other := KeyPairFromPassword("eve", RolePosting, "e")
+	message := []byte("login:1700000000:c2b9")
+
+	sig, err := SignMessage(message, keyPair)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sig) != 130 {
+		t.Error("Expected a 65 byte hex signature, got", sig)
+	}
+
+	if err = VerifyMessage(message, sig, keyPair.PublicKey); err != nil {
+		t.Error(err)
+	}
+	if err = VerifyMessage(message, sig, other.PublicKey); err == nil {
+		t.Error("Expected another key to be rejected")
+	}
+	if err = VerifyMessage([]byte("login:1700000001:c2b9"), sig, keyPair.PublicKey); err == nil {
+		t.Error("Expected another message to be rejected")
+	}
+
+	privKey, _ := PrivateKeyFromWif("5JuMt237G3m3BaT7zH4YdoycUtbw4AEPy6DLdCrKAnFGAtXyQ1W")
+	if got, err := SignMessage(message, privKey); err != nil || got != sig {
+		t.Error("Expected a PrivateKey to give the same signature, got", got, err)
+	}
+}
+
+func TestVerifyAccountMessage(t *testing.T) {
+	node, h := newTestNode(t)
+	keyPair, _ := KeyPairFromWif("5JuMt237G3m3BaT7zH4YdoycUtbw4AEPy6DLdCrKAnFGAtXyQ1W")
+	node.handle("condenser_api.get_accounts", func(json.RawMessage) (interface{}, error) {
+		return []map[string]interface{}{{
+			"name":    "xeroc",
+			"posting": map[string]interface{}{"weight_threshold": 1, "key_auths": [][]interface{}{{*keyPair.GetPublicKeyString(), 1}}},
+			"active":  map[string]interface{}{"weight_threshold": 1, "key_auths": [][]interface{}{}},
+		}}, nil
+	})
+
+	message := []byte("login:1700000000:c2b9")
+	sig, _ := SignMessage(message, keyPair)
+	if err := h.VerifyAccountMessage("xeroc", RolePosting, message, sig); err != nil {
+		t.Error(err)
+	}
+	if err := h.VerifyAccountMessage("xeroc", RoleActive, message, sig); err == nil {
+		t.Error("Expected the active authority not to be satisfied")
+	}
+
+	otherSig, _ := SignMessage(message, KeyPairFromPassword("eve", RolePosting, "e"))
+	if err := h.VerifyAccountMessage("xeroc", RolePosting, message, otherSig); err == nil {
+		t.Error("Expected a signature from another key to be rejected")
+	}
+}
@@ -0,0 +1,142 @@
+package hivego
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v2"
+)
+
+// AssetSymbols names the liquid, HBD and vesting assets of a network
+type AssetSymbols struct {
+	Hive  string
+	Hbd   string
+	Vests string
+}
+
+// NetworkConfig describes the chain a client signs for: its chain id, the
+// prefix of its public keys and the symbols of its assets
+type NetworkConfig struct {
+	// ChainId is the hex encoded chain id mixed into every signature digest
+	ChainId       string
+	AddressPrefix string
+	// Symbols are the symbols used in asset strings such as "1.000 HIVE"
+	Symbols AssetSymbols
+	// SerializedSymbols are the symbols written when assets are serialized
+	SerializedSymbols AssetSymbols
+}
+
+var MainnetConfig = NetworkConfig{
+	ChainId:       "beeab0de00000000000000000000000000000000000000000000000000000000",
+	AddressPrefix: "STM",
+	Symbols:       AssetSymbols{Hive: "HIVE", Hbd: "HBD", Vests: "VESTS"},
+	// assets keep their pre-Hive names on the wire
+	SerializedSymbols: AssetSymbols{Hive: "STEEM", Hbd: "SBD", Vests: "VESTS"},
+}
+
+var TestnetConfig = NetworkConfig{
+	ChainId:           "18dcf0a285365fc58b71f18b3d3fec954aa0c141c44e4e5cb4cf777b9eab274e",
+	AddressPrefix:     "TST",
+	Symbols:           AssetSymbols{Hive: "TESTS", Hbd: "TBD", Vests: "VESTS"},
+	SerializedSymbols: AssetSymbols{Hive: "TESTS", Hbd: "TBD", Vests: "VESTS"},
+}
+
+var MirrornetConfig = NetworkConfig{
+	ChainId:           "4200000000000000000000000000000000000000000000000000000000000000",
+	AddressPrefix:     "TST",
+	Symbols:           AssetSymbols{Hive: "TESTS", Hbd: "TBD", Vests: "VESTS"},
+	SerializedSymbols: AssetSymbols{Hive: "TESTS", Hbd: "TBD", Vests: "VESTS"},
+}
+
+// orMainnet lets a nil config stand for mainnet
+func (n *NetworkConfig) orMainnet() *NetworkConfig {
+	if n == nil {
+		return &MainnetConfig
+	}
+	return n
+}
+
+// Validate checks that the chain id is 32 hex encoded bytes and that the key
+// prefix and asset symbols are set, so a typo cannot silently sign for the
+// wrong chain
+func (n *NetworkConfig) Validate() error {
+	n = n.orMainnet()
+	if _, err := n.chainId(); err != nil {
+		return err
+	}
+	if n.AddressPrefix == "" {
+		return errors.New("network has no address prefix")
+	}
+	for _, symbols := range []AssetSymbols{n.Symbols, n.SerializedSymbols} {
+		if symbols.Hive == "" || symbols.Hbd == "" || symbols.Vests == "" {
+			return errors.New("network is missing asset symbols")
+		}
+	}
+	return nil
+}
+
+func (n *NetworkConfig) chainId() ([]byte, error) {
+	cid, err := hex.DecodeString(n.orMainnet().ChainId)
+	if err != nil || len(cid) != 32 {
+		return nil, fmt.Errorf("invalid chain id %q: must be 32 hex encoded bytes", n.orMainnet().ChainId)
+	}
+	return cid, nil
+}
+
+// DecodePublicKey decodes a public key carrying this network's prefix
+func (n *NetworkConfig) DecodePublicKey(pubKey string) (*secp256k1.PublicKey, error) {
+	return decodePublicKey(pubKey, n.orMainnet().AddressPrefix)
+}
+
+// PublicKeyString encodes a public key with this network's prefix
+func (n *NetworkConfig) PublicKeyString(pubKey *secp256k1.PublicKey) *string {
+	return publicKeyString(pubKey, n.orMainnet().AddressPrefix)
+}
+
+// serializedSymbol maps an asset string symbol to the symbol it is serialized
+// as, along with the asset's precision
+func (n *NetworkConfig) serializedSymbol(symbol string) (string, int, bool) {
+	n = n.orMainnet()
+	switch symbol {
+	case n.Symbols.Hive:
+		return n.SerializedSymbols.Hive, 3, true
+	case n.Symbols.Hbd:
+		return n.SerializedSymbols.Hbd, 3, true
+	case n.Symbols.Vests:
+		return n.SerializedSymbols.Vests, 6, true
+	}
+	return "", 0, false
+}
+
+// decodeKnownPublicKey decodes a public key carrying the prefix of any of the
+// built in networks
+func decodeKnownPublicKey(pubKey string) (*secp256k1.PublicKey, error) {
+	k, err := ParsePublicKey(pubKey)
+	if err != nil {
+		return nil, err
+	}
+	return k.Key()
+}
+
+// networkOperation is implemented by operations whose serialization depends
+// on the network they were built for
+type networkOperation interface {
+	network() *NetworkConfig
+}
+
+func (o claimRewardOperation) network() *NetworkConfig {
+	return o.net
+}
+
+func (o transferOperation) network() *NetworkConfig {
+	return o.net
+}
+
+func (o accountUpdateOperation) network() *NetworkConfig {
+	return o.net
+}
+
+func (h *HiveRpcNode) network() *NetworkConfig {
+	return &h.net
+}
@@ -0,0 +1,147 @@
+package hivego
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestNetworkPublicKeyPrefix(t *testing.T) {
+	keyPair, _ := KeyPairFromWif("5JuMt237G3m3BaT7zH4YdoycUtbw4AEPy6DLdCrKAnFGAtXyQ1W")
+
+	tstKey := *TestnetConfig.PublicKeyString(keyPair.PublicKey)
+	if tstKey != "TST"+strings.TrimPrefix(*keyPair.GetPublicKeyString(), "STM") {
+		t.Error("Expected a TST key, got", tstKey)
+	}
+
+	decoded, err := TestnetConfig.DecodePublicKey(tstKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.IsEqual(keyPair.PublicKey) {
+		t.Error("Expected the decoded key to match")
+	}
+
+	if _, err = MainnetConfig.DecodePublicKey(tstKey); err == nil {
+		t.Error("Expected mainnet to reject a TST key")
+	}
+}
+
+func TestPackageKeyFunctionsUseMainnet(t *testing.T) {
+	keyPair, _ := KeyPairFromWif("5JuMt237G3m3BaT7zH4YdoycUtbw4AEPy6DLdCrKAnFGAtXyQ1W")
+	expected := *MainnetConfig.PublicKeyString(keyPair.PublicKey)
+
+	saved := PublicKeyPrefix
+	PublicKeyPrefix = "TST"
+	defer func() { PublicKeyPrefix = saved }()
+
+	if got := *GetPublicKeyString(keyPair.PublicKey); got != expected {
+		t.Error("Expected", expected, "got", got)
+	}
+	if got := NewPublicKey(keyPair.PublicKey).String(); got != expected {
+		t.Error("Expected", expected, "got", got)
+	}
+	if _, err := DecodePublicKey(expected); err != nil {
+		t.Error("Expected a mainnet key to decode, got", err)
+	}
+}
+
+func TestNetworkAssetSymbols(t *testing.T) {
+	op := transferOperation{"xeroc", "piston", "1.000 TESTS", "", "transfer", &TestnetConfig}
+	if err := op.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	opB, err := op.SerializeOp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(opB, []byte{3, 'T', 'E', 'S', 'T', 'S', 0, 0}) {
+		t.Errorf("Expected the amount to be serialized as TESTS, got %x", opB)
+	}
+
+	op.net = nil
+	if err := op.Validate(); err == nil {
+		t.Error("Expected mainnet to reject TESTS")
+	}
+	if _, err := op.SerializeOp(); err == nil {
+		t.Error("Expected mainnet serialization to reject TESTS")
+	}
+
+	op.Amount = "1.000 HIVE"
+	opB, _ = op.SerializeOp()
+	if !bytes.Contains(opB, []byte{3, 'S', 'T', 'E', 'E', 'M', 0, 0}) {
+		t.Errorf("Expected HIVE to be serialized as STEEM, got %x", opB)
+	}
+}
+
+func TestNetworkSigning(t *testing.T) {
+	_, h := newTestNode(t, WithNetwork(TestnetConfig))
+	signer, _ := NewKeySigner("5JuMt237G3m3BaT7zH4YdoycUtbw4AEPy6DLdCrKAnFGAtXyQ1W")
+	keyPair, _ := KeyPairFromWif("5JuMt237G3m3BaT7zH4YdoycUtbw4AEPy6DLdCrKAnFGAtXyQ1W")
+
+	res, err := h.DryRun([]HiveOperation{getTestVoteOp()}, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tstKey := *TestnetConfig.PublicKeyString(keyPair.PublicKey)
+	if len(res.SigningKeys) != 1 || res.SigningKeys[0] != tstKey {
+		t.Error("Expected signing key", tstKey, "got", res.SigningKeys)
+	}
+
+	tx, err := TestnetConfig.ParseTransactionJson(res.Json)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tx.VerifySignatures([]string{tstKey}); err != nil {
+		t.Error(err)
+	}
+
+	// the same signatures do not cover the mainnet digest
+	mainnetTx, _ := ParseTransactionJson(res.Json)
+	if err = mainnetTx.VerifySignatures([]string{*keyPair.GetPublicKeyString()}); err == nil {
+		t.Error("Expected testnet signatures to fail verification on mainnet")
+	}
+	if _, err = h.BroadcastTransaction(mainnetTx); err == nil {
+		t.Error("Expected a mainnet transaction to be rejected by a testnet node")
+	}
+
+	mainnetTransfer, _ := ParseOperationJson([]byte(`["transfer",{"from":"xeroc","to":"piston","amount":"1.000 HIVE","memo":""}]`))
+	if _, err = h.DryRun([]HiveOperation{mainnetTransfer}, signer); err == nil {
+		t.Error("Expected a mainnet operation to be rejected by a testnet node")
+	}
+}
+
+func TestNetworkValidate(t *testing.T) {
+	for _, net := range []NetworkConfig{MainnetConfig, TestnetConfig, MirrornetConfig} {
+		if err := net.Validate(); err != nil {
+			t.Error("Expected", net.AddressPrefix, "to be valid, got", err)
+		}
+	}
+
+	bad := map[string]func(*NetworkConfig){
+		"short chain id":    func(n *NetworkConfig) { n.ChainId = "beeab0de" },
+		"non hex chain id":  func(n *NetworkConfig) { n.ChainId = "beeab0de0000000000000000000000000000000000000000000000000000000g" },
+		"no prefix":         func(n *NetworkConfig) { n.AddressPrefix = "" },
+		"no symbol":         func(n *NetworkConfig) { n.Symbols.Hbd = "" },
+		"no serialized one": func(n *NetworkConfig) { n.SerializedSymbols.Vests = "" },
+	}
+	for name, mutate := range bad {
+		net := MainnetConfig
+		mutate(&net)
+		if err := net.Validate(); err == nil {
+			t.Error("Expected an error for", name)
+		}
+	}
+
+	// a node with an invalid network refuses to sign
+	net := MainnetConfig
+	net.ChainId = "beeab0de"
+	node, h := newTestNode(t, WithNetwork(net))
+	signer, _ := NewKeySigner("5JuMt237G3m3BaT7zH4YdoycUtbw4AEPy6DLdCrKAnFGAtXyQ1W")
+	if _, err := h.Broadcast([]HiveOperation{getTestVoteOp()}, signer); err == nil {
+		t.Error("Expected an invalid chain id to be rejected")
+	}
+	if len(node.broadcastOps()) != 0 {
+		t.Error("Expected nothing to be broadcast")
+	}
+}
@@ -0,0 +1,125 @@
+package hivego
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+type condenserTransaction struct {
+	RefBlockNum    uint16               `json:"ref_block_num"`
+	RefBlockPrefix uint32               `json:"ref_block_prefix"`
+	Expiration     string               `json:"expiration"`
+	Operations     [][2]json.RawMessage `json:"operations"`
+	Extensions     []json.RawMessage    `json:"extensions"`
+	Signatures     []string             `json:"signatures"`
+}
+
+// ParseTransactionJson parses a condenser format transaction, with operations
+// given as [op_name, {...}] pairs, into a HiveTransaction that can be
+// serialized, signed and broadcast. Operations hivego cannot serialize are
+// rejected rather than dropped.
+func ParseTransactionJson(data []byte) (*HiveTransaction, error) {
+	return MainnetConfig.ParseTransactionJson(data)
+}
+
+// ParseTransactionJson parses a transaction signed for this network
+func (n *NetworkConfig) ParseTransactionJson(data []byte) (*HiveTransaction, error) {
+	var ctx condenserTransaction
+	err := json.Unmarshal(data, &ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ctx.Extensions) > 0 {
+		return nil, errors.New("transaction extensions are not supported")
+	}
+
+	tx := &HiveTransaction{
+		RefBlockNum:    ctx.RefBlockNum,
+		RefBlockPrefix: ctx.RefBlockPrefix,
+		Expiration:     ctx.Expiration,
+		Signatures:     ctx.Signatures,
+		network:        n,
+	}
+	for i, opPair := range ctx.Operations {
+		op, err := parseOperation(opPair, n)
+		if err != nil {
+			return nil, fmt.Errorf("operation %d: %w", i, err)
+		}
+		tx.Operations = append(tx.Operations, op)
+	}
+
+	if _, err = expTimeB(tx.Expiration); err != nil {
+		return nil, err
+	}
+
+	tx.prepareJson()
+	return tx, nil
+}
+
+// ParseOperationJson parses a single condenser format [op_name, {...}] operation
+func ParseOperationJson(data []byte) (HiveOperation, error) {
+	return MainnetConfig.ParseOperationJson(data)
+}
+
+// ParseOperationJson parses a single operation for this network
+func (n *NetworkConfig) ParseOperationJson(data []byte) (HiveOperation, error) {
+	var opPair [2]json.RawMessage
+	err := json.Unmarshal(data, &opPair)
+	if err != nil {
+		return nil, err
+	}
+	return parseOperation(opPair, n)
+}
+
+func parseOperation(opPair [2]json.RawMessage, net *NetworkConfig) (HiveOperation, error) {
+	var name string
+	err := json.Unmarshal(opPair[0], &name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid operation name: %w", err)
+	}
+	body := opPair[1]
+
+	switch name {
+	case "vote":
+		var op voteOperation
+		err = decodeOpBody(body, &op)
+		op.opText = name
+		return op, err
+	case "custom_json":
+		var op customJsonOperation
+		err = decodeOpBody(body, &op)
+		op.opText = name
+		return op, err
+	case "claim_reward_balance":
+		var op claimRewardOperation
+		err = decodeOpBody(body, &op)
+		op.opText = name
+		op.net = net
+		return op, err
+	case "transfer":
+		var op transferOperation
+		err = decodeOpBody(body, &op)
+		op.opText = name
+		op.net = net
+		return op, err
+	case "account_update":
+		var op accountUpdateOperation
+		err = decodeOpBody(body, &op)
+		op.opText = name
+		op.net = net
+		return op, err
+	}
+
+	return nil, errors.New("unsupported operation: " + name)
+}
+
+// decodeOpBody rejects fields the operation type does not know about, since
+// they would otherwise be left out of the serialized transaction
+func decodeOpBody(body json.RawMessage, op interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+	return dec.Decode(op)
+}
@@ -0,0 +1,104 @@
+package hivego
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestParseTransactionJson(t *testing.T) {
+	data := []byte(`{"ref_block_num":36029,"ref_block_prefix":1164960351,"expiration":"2016-08-08T12:24:17","operations":[["vote",{"voter":"xeroc","author":"xeroc","permlink":"piston","weight":10000}]],"extensions":[],"signatures":[]}`)
+	tx, err := ParseTransactionJson(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, _ := serializeTx(*tx)
+	expected, _ := serializeTx(getTestVoteTx())
+	if !bytes.Equal(got, expected) {
+		t.Error("Expected", expected, "got", got)
+	}
+
+	txId, _ := tx.TxId()
+	if txId != "12164dcee518674c586e6a61d08623c44980e326" {
+		t.Error("Expected", "12164dcee518674c586e6a61d08623c44980e326", "got", txId)
+	}
+}
+
+func TestParseOperationJsonCustomJson(t *testing.T) {
+	op, err := ParseOperationJson([]byte(`["custom_json",{"required_auths":[],"required_posting_auths":["xeroc"],"id":"test-id","json":"{\"testk\":\"testv\"}"}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, _ := op.SerializeOp()
+	expected, _ := getTestCustomJsonOp().SerializeOp()
+	if !bytes.Equal(got, expected) {
+		t.Error("Expected", expected, "got", got)
+	}
+}
+
+func TestParseOperationJsonAccountUpdateAuths(t *testing.T) {
+	op, err := ParseOperationJson([]byte(`["account_update",{"account":"sniperduel17","posting":{"weight_threshold":1,"account_auths":[["xeroc",1]],"key_auths":[["STM6n4WcwyiC63udKYR8jDFuzG9T48dhy2Qb5sVmQ9MyNuKM7xE29",1]]},"memo_key":"STM6n4WcwyiC63udKYR8jDFuzG9T48dhy2Qb5sVmQ9MyNuKM7xE29","json_metadata":""}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := op.SerializeOp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// op id, account, no owner, no active, posting present with threshold 1,
+	// one account auth and one 33 byte key auth
+	expectedLen := 1 + 13 + 1 + 1 + 1 + 4 + 1 + 6 + 2 + 1 + 33 + 2 + 33 + 1
+	if len(got) != expectedLen {
+		t.Error("Expected", expectedLen, "bytes, got", len(got))
+	}
+}
+
+func TestAccountUpdateAuthsMustBeSorted(t *testing.T) {
+	keyA, keyB := "STM6n4WcwyiC63udKYR8jDFuzG9T48dhy2Qb5sVmQ9MyNuKM7xE29", "STM6LLegbAgLAy28EHrffBVuANFWcFgmqRMW13wBmTExqFE9SCkg4"
+	a, _ := ParsePublicKey(keyA)
+	b, _ := ParsePublicKey(keyB)
+	if bytes.Compare(a.data[:], b.data[:]) > 0 {
+		keyA, keyB = keyB, keyA
+	}
+	op := func(accounts string, keys string) string {
+		return `["account_update",{"account":"sniperduel17","posting":{"weight_threshold":1,"account_auths":` + accounts + `,"key_auths":` + keys + `},"memo_key":"` + keyA + `","json_metadata":""}]`
+	}
+	sortedAccounts, sortedKeys := `[["alice",1],["bob",1]]`, `[["`+keyA+`",1],["`+keyB+`",1]]`
+
+	for _, tc := range []struct {
+		json  string
+		valid bool
+	}{
+		{op(sortedAccounts, sortedKeys), true},
+		{op(`[["bob",1],["alice",1]]`, sortedKeys), false},
+		{op(`[["alice",1],["alice",1]]`, sortedKeys), false},
+		{op(sortedAccounts, `[["`+keyB+`",1],["`+keyA+`",1]]`), false},
+		{op(sortedAccounts, `[["`+keyA+`",1],["`+keyA+`",1]]`), false},
+	} {
+		parsed, err := ParseOperationJson([]byte(tc.json))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var vErr *ValidationError
+		err = parsed.Validate()
+		if tc.valid && err != nil {
+			t.Error("Expected sorted auths to be valid, got", err)
+		}
+		if !tc.valid && (!errors.As(err, &vErr) || vErr.Field != "posting") {
+			t.Error("Expected posting validation error for", tc.json, "got", err)
+		}
+	}
+}
+
+func TestParseOperationJsonRejectsUnknown(t *testing.T) {
+	if _, err := ParseOperationJson([]byte(`["comment",{"author":"xeroc"}]`)); err == nil {
+		t.Error("Expected error for unsupported operation")
+	}
+
+	if _, err := ParseOperationJson([]byte(`["vote",{"voter":"xeroc","author":"xeroc","permlink":"piston","weight":10000,"extra":1}]`)); err == nil {
+		t.Error("Expected error for unknown operation field")
+	}
+}
@@ -0,0 +1,164 @@
+package hivego
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v2"
+)
+
+// ErrKeyDestroyed is returned when signing with a destroyed PrivateKey
+var ErrKeyDestroyed = errors.New("private key has been destroyed")
+
+// PrivateKey holds a private key in a buffer that Destroy zeroes. It only
+// ever prints or marshals its public key, and implements Signer. It is safe
+// for concurrent use.
+//
+// Signing works on a scalar built from the buffer for the duration of each
+// signature and wiped afterwards, along with the nonce and the intermediates
+// derived from the key. The buffers NonceRFC6979 uses internally, and storage
+// math/big reallocates, cannot be reached and are left to the garbage
+// collector.
+type PrivateKey struct {
+	mu     sync.RWMutex
+	secret []byte
+	pub    *secp256k1.PublicKey
+}
+
+// PrivateKeyFromWif decodes wif into a PrivateKey, zeroing the decoded bytes
+func PrivateKeyFromWif(wif string) (*PrivateKey, error) {
+	payload, _, err := GphBase58CheckDecode(wif)
+	if err != nil {
+		return nil, err
+	}
+	defer zero(payload)
+	return PrivateKeyFromBytes(payload)
+}
+
+// PrivateKeyFromBytes copies a 32 byte secret into a PrivateKey. The caller
+// may zero secret afterwards.
+func PrivateKeyFromBytes(secret []byte) (*PrivateKey, error) {
+	if len(secret) != 32 {
+		return nil, errors.New("private key must be 32 bytes")
+	}
+	priv, pub := secp256k1.PrivKeyFromBytes(secret)
+	valid := priv.D.Sign() > 0 && priv.D.Cmp(secp256k1.S256().N) < 0
+	wipeKey(priv)
+	if !valid {
+		return nil, errors.New("invalid private key")
+	}
+	return &PrivateKey{secret: append(make([]byte, 0, 32), secret...), pub: pub}, nil
+}
+
+// Destroy zeroes the key. Signing fails with ErrKeyDestroyed afterwards.
+func (k *PrivateKey) Destroy() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	zero(k.secret)
+	k.secret = nil
+}
+
+func (k *PrivateKey) Destroyed() bool {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.secret == nil
+}
+
+func (k *PrivateKey) PublicKey() *secp256k1.PublicKey {
+	return k.pub
+}
+
+// Sign returns a canonical compact signature of digest
+func (k *PrivateKey) Sign(digest []byte) ([]byte, error) {
+	var sig []byte
+	err := k.withKey(func(key *secp256k1.PrivateKey) error {
+		var err error
+		sig, err = signCanonical(key, digest)
+		return err
+	})
+	return sig, err
+}
+
+func (k *PrivateKey) PublicKeys() []*secp256k1.PublicKey {
+	return []*secp256k1.PublicKey{k.pub}
+}
+
+func (k *PrivateKey) SignDigest(digest []byte) ([][]byte, error) {
+	sig, err := k.Sign(digest)
+	if err != nil {
+		return nil, err
+	}
+	return [][]byte{sig}, nil
+}
+
+// withKey runs fn with a secp256k1 key built from the buffer, wiping it after
+func (k *PrivateKey) withKey(fn func(*secp256k1.PrivateKey) error) error {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	if k.secret == nil {
+		return ErrKeyDestroyed
+	}
+
+	key := &secp256k1.PrivateKey{PublicKey: ecdsa.PublicKey(*k.pub), D: new(big.Int).SetBytes(k.secret)}
+	defer wipeKey(key)
+	return fn(key)
+}
+
+// appendSecret appends the 32 byte secret to dst, which the caller must zero
+func (k *PrivateKey) appendSecret(dst []byte) ([]byte, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	if k.secret == nil {
+		return dst, ErrKeyDestroyed
+	}
+	return append(dst, k.secret...), nil
+}
+
+// String names the key by its compressed public key in hex, since a
+// PrivateKey does not know which network's prefix to print
+func (k *PrivateKey) String() string {
+	return "PrivateKey(" + k.publicKeyHex() + ")"
+}
+
+func (k *PrivateKey) publicKeyHex() string {
+	if k == nil || k.pub == nil {
+		return ""
+	}
+	return hex.EncodeToString(k.pub.SerializeCompressed())
+}
+
+// Format prints String for every verb, so %v, %+v and %#v never show the key
+func (k *PrivateKey) Format(f fmt.State, verb rune) {
+	fmt.Fprint(f, k.String())
+}
+
+// MarshalJSON encodes the public key as String does, never the secret
+func (k *PrivateKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(k.publicKeyHex())
+}
+
+// wipeKey zeroes the words of a secp256k1 key's scalar in place
+func wipeKey(key *secp256k1.PrivateKey) {
+	wipeInt(key.D)
+}
+
+// wipeInt zeroes the words backing x. Storage math/big has already
+// reallocated away from is out of reach.
+func wipeInt(x *big.Int) {
+	words := x.Bits()
+	for i := range words {
+		words[i] = 0
+	}
+	x.SetInt64(0)
+}
+
+func zero(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
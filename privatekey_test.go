@@ -0,0 +1,80 @@
+package hivego
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestPrivateKeyNeverPrinted(t *testing.T) {
+	wif := "5JuMt237G3m3BaT7zH4YdoycUtbw4AEPy6DLdCrKAnFGAtXyQ1W"
+	key, err := PrivateKeyFromWif(wif)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPair, _ := KeyPairFromWif(wif)
+	secretHex := hex.EncodeToString(keyPair.PrivateKey.Serialize())
+
+	jsonB, err := json.Marshal(struct{ Key *PrivateKey }{key})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, out := range []string{key.String(), fmt.Sprint(key), fmt.Sprintf("%+v %#v %s %x", key, key, key, key), string(jsonB)} {
+		if strings.Contains(out, wif) || strings.Contains(out, secretHex) {
+			t.Error("Expected the secret not to be printed, got", out)
+		}
+		if !strings.Contains(out, hex.EncodeToString(keyPair.PublicKey.SerializeCompressed())) {
+			t.Error("Expected the public key to be printed, got", out)
+		}
+	}
+
+	// neither a zero value nor a destroyed key may panic when printed
+	var zeroKey PrivateKey
+	if got := fmt.Sprint(&zeroKey); got != "PrivateKey()" {
+		t.Error("Expected an empty key, got", got)
+	}
+	key.Destroy()
+	_ = key.String()
+}
+
+func TestPrivateKeyDestroy(t *testing.T) {
+	wif := "5JuMt237G3m3BaT7zH4YdoycUtbw4AEPy6DLdCrKAnFGAtXyQ1W"
+	key, _ := PrivateKeyFromWif(wif)
+	digest := make([]byte, 32)
+
+	sig, err := key.Sign(digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, _ := SignDigest(digest, &wif)
+	if !bytes.Equal(sig, expected) {
+		t.Error("Expected", expected, "got", sig)
+	}
+
+	secret := key.secret
+	key.Destroy()
+	if !key.Destroyed() || !bytes.Equal(secret, make([]byte, 32)) {
+		t.Error("Expected the secret to be zeroed")
+	}
+	if _, err := key.Sign(digest); !errors.Is(err, ErrKeyDestroyed) {
+		t.Error("Expected ErrKeyDestroyed, got", err)
+	}
+
+	signer, _ := NewKeySigner(wif)
+	signer.Destroy()
+	if _, err := signer.SignDigest(digest); !errors.Is(err, ErrKeyDestroyed) {
+		t.Error("Expected ErrKeyDestroyed from a destroyed signer, got", err)
+	}
+}
+
+func TestPrivateKeyFromBytesInvalid(t *testing.T) {
+	for _, secret := range [][]byte{nil, make([]byte, 31), make([]byte, 32), bytes.Repeat([]byte{0xff}, 32)} {
+		if _, err := PrivateKeyFromBytes(secret); err == nil {
+			t.Error("Expected an error for", secret)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package hivego
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/decred/base58"
+	"github.com/decred/dcrd/dcrec/secp256k1/v2"
+
+	//lint:ignore SA1019 ripemd160 is used for checksums of public keys and is required for compatibility with Hive
+	"golang.org/x/crypto/ripemd160"
+)
+
+// PublicKey is a Hive public key such as STM6n4Wc..., as found in account
+// authorities and memo keys. It is encoded as its string in JSON and text.
+// The zero value is an empty key, and the all zero null key hived gives
+// accounts such as "null" is accepted too.
+type PublicKey struct {
+	data   [33]byte
+	prefix string
+}
+
+// NewPublicKey wraps key with the mainnet prefix
+func NewPublicKey(key *secp256k1.PublicKey) PublicKey {
+	return MainnetConfig.NewPublicKey(key)
+}
+
+// NewPublicKey wraps key with this network's prefix
+func (n *NetworkConfig) NewPublicKey(key *secp256k1.PublicKey) PublicKey {
+	return newPublicKey(key, n.orMainnet().AddressPrefix)
+}
+
+func newPublicKey(key *secp256k1.PublicKey, prefix string) PublicKey {
+	k := PublicKey{prefix: prefix}
+	copy(k.data[:], key.SerializeCompressed())
+	return k
+}
+
+// ParsePublicKey parses a public key with any network prefix, keeping the
+// prefix it was written with. Keys of custom networks are split where the
+// rest of the string decodes to a key with a valid checksum.
+func ParsePublicKey(s string) (PublicKey, error) {
+	for _, prefix := range []string{MainnetConfig.AddressPrefix, TestnetConfig.AddressPrefix} {
+		if strings.HasPrefix(s, prefix) {
+			return parsePublicKey(s, prefix)
+		}
+	}
+	for i := 1; i < len(s) && isPrefixLetter(s[i-1]); i++ {
+		if k, err := parsePublicKey(s, s[:i]); err == nil {
+			return k, nil
+		}
+	}
+	return PublicKey{}, errors.New("invalid public key prefix")
+}
+
+func isPrefixLetter(c byte) bool {
+	return c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z'
+}
+
+// ParsePublicKey parses a public key carrying this network's prefix
+func (n *NetworkConfig) ParsePublicKey(s string) (PublicKey, error) {
+	return parsePublicKey(s, n.orMainnet().AddressPrefix)
+}
+
+func parsePublicKey(s string, prefix string) (PublicKey, error) {
+	if !strings.HasPrefix(s, prefix) {
+		return PublicKey{}, errors.New("invalid public key prefix")
+	}
+
+	decoded := base58.Decode(s[len(prefix):])
+	if len(decoded) != 33+4 {
+		return PublicKey{}, errors.New("invalid public key length")
+	}
+	if !bytes.Equal(decoded[33:], publicKeyChecksum(decoded[:33])) {
+		return PublicKey{}, errors.New("checksums do not match")
+	}
+
+	k := PublicKey{prefix: prefix}
+	copy(k.data[:], decoded[:33])
+	if !k.isNull() {
+		if _, err := secp256k1.ParsePubKey(k.data[:]); err != nil {
+			return PublicKey{}, err
+		}
+	}
+	return k, nil
+}
+
+func publicKeyChecksum(data []byte) []byte {
+	hasher := ripemd160.New()
+	hasher.Write(data)
+	return hasher.Sum(nil)[:4]
+}
+
+func (k PublicKey) isNull() bool {
+	return k.data == [33]byte{}
+}
+
+// IsZero reports whether the key is empty
+func (k PublicKey) IsZero() bool {
+	return k.prefix == ""
+}
+
+// Key returns the secp256k1 key, failing for empty and null keys
+func (k PublicKey) Key() (*secp256k1.PublicKey, error) {
+	if k.IsZero() {
+		return nil, errors.New("empty public key")
+	}
+	if k.isNull() {
+		return nil, errors.New("null public key")
+	}
+	return secp256k1.ParsePubKey(k.data[:])
+}
+
+// Prefix returns the network prefix the key was written with
+func (k PublicKey) Prefix() string {
+	return k.prefix
+}
+
+// Equal reports whether both are the same key, whatever their prefixes
+func (k PublicKey) Equal(other PublicKey) bool {
+	return k.IsZero() == other.IsZero() && k.data == other.data
+}
+
+func (k PublicKey) String() string {
+	if k.IsZero() {
+		return ""
+	}
+	return k.prefix + base58.Encode(append(k.data[:], publicKeyChecksum(k.data[:])...))
+}
+
+func (k PublicKey) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// UnmarshalText parses a key with any network prefix, leaving the key empty
+// for empty text. The prefix is only checked against a network when the key
+// is serialized.
+func (k *PublicKey) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*k = PublicKey{}
+		return nil
+	}
+	parsed, err := ParsePublicKey(string(text))
+	if err != nil {
+		return err
+	}
+	*k = parsed
+	return nil
+}
+
+func (k PublicKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(k.String())
+}
+
+func (k *PublicKey) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	return k.UnmarshalText([]byte(s))
+}
+
+// bytesFor returns the serialized key, checking it is written for network n
+func (k PublicKey) bytesFor(n *NetworkConfig) ([]byte, error) {
+	if k.IsZero() {
+		return nil, errors.New("empty public key")
+	}
+	if prefix := n.orMainnet().AddressPrefix; k.prefix != prefix {
+		return nil, fmt.Errorf("public key %s does not have the network prefix %s", k, prefix)
+	}
+	return k.data[:], nil
+}
+
+// KeyAuth is a key and its weight in an authority, a [key, weight] pair in
+// JSON
+type KeyAuth struct {
+	Key    PublicKey
+	Weight uint16
+}
+
+func (a KeyAuth) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]interface{}{a.Key, a.Weight})
+}
+
+func (a *KeyAuth) UnmarshalJSON(data []byte) error {
+	var pair []json.RawMessage
+	if err := json.Unmarshal(data, &pair); err != nil {
+		return err
+	}
+	if len(pair) != 2 {
+		return fmt.Errorf("invalid key auth: %s", data)
+	}
+	if err := json.Unmarshal(pair[0], &a.Key); err != nil {
+		return err
+	}
+	return json.Unmarshal(pair[1], &a.Weight)
+}
@@ -0,0 +1,74 @@
+package hivego
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParsePublicKey(t *testing.T) {
+	s := "STM6n4WcwyiC63udKYR8jDFuzG9T48dhy2Qb5sVmQ9MyNuKM7xE29"
+	k, err := ParsePublicKey(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k.String() != s {
+		t.Error("Expected", s, "got", k.String())
+	}
+
+	key, _ := k.Key()
+	tst := TestnetConfig.NewPublicKey(key)
+	if !tst.Equal(k) || tst.String() != "TST"+s[3:] {
+		t.Error("Expected the same key with the testnet prefix, got", tst)
+	}
+	if _, err := TestnetConfig.ParsePublicKey(s); err == nil {
+		t.Error("Expected a mainnet key to be rejected on testnet")
+	}
+
+	null, err := ParsePublicKey("STM1111111111111111111111111111111114T1Anm")
+	if err != nil {
+		t.Fatal("Expected the null key to parse, got", err)
+	}
+	if _, err := null.Key(); err == nil {
+		t.Error("Expected the null key to have no secp256k1 key")
+	}
+
+	// keys of custom networks keep their prefix
+	custom, err := ParsePublicKey("MYCHAIN" + s[3:])
+	if err != nil || custom.Prefix() != "MYCHAIN" || !custom.Equal(k) {
+		t.Error("Expected the key with a custom prefix, got", custom, err)
+	}
+
+	for _, bad := range []string{"", "STM", "STM1", "STMSTM", "1" + s[3:], s[:len(s)-1], s + "1", s[:10] + "0" + s[11:]} {
+		if _, err := ParsePublicKey(bad); err == nil {
+			t.Error("Expected an error for", bad)
+		}
+	}
+}
+
+func TestPublicKeyJson(t *testing.T) {
+	var account AccountData
+	err := json.Unmarshal([]byte(`{
+		"memo_key": "STM6n4WcwyiC63udKYR8jDFuzG9T48dhy2Qb5sVmQ9MyNuKM7xE29",
+		"active": {"weight_threshold": 1, "key_auths": [["STM6n4WcwyiC63udKYR8jDFuzG9T48dhy2Qb5sVmQ9MyNuKM7xE29", 1]]}
+	}`), &account)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !account.Active.KeyAuths[0].Key.Equal(account.MemoKey) || account.Active.KeyAuths[0].Weight != 1 {
+		t.Error("Unexpected key auths", account.Active.KeyAuths)
+	}
+
+	b, err := json.Marshal(account.Active.KeyAuths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `[["STM6n4WcwyiC63udKYR8jDFuzG9T48dhy2Qb5sVmQ9MyNuKM7xE29",1]]`; string(b) != expected {
+		t.Error("Expected", expected, "got", string(b))
+	}
+
+	for _, bad := range []string{`{"memo_key": "STM1"}`, `{"memo_key": 1}`, `{"active": {"key_auths": [["STM1"]]}}`} {
+		if err := json.Unmarshal([]byte(bad), &account); err == nil {
+			t.Error("Expected an error for", bad)
+		}
+	}
+}
@@ -0,0 +1,277 @@
+package hivego
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+const (
+	// rcRegenTime is hived's HIVE_RC_REGEN_TIME: a fully drained manabar
+	// regenerates over five days
+	rcRegenTime = 5 * 24 * time.Hour
+
+	blockInterval = 3 * time.Second
+)
+
+// InsufficientRCError is returned by broadcasts made with WithCheckRC when the
+// paying account lacks the resource credits the transaction is estimated to cost
+type InsufficientRCError struct {
+	Account   string
+	Available int64
+	Required  int64
+}
+
+func (e *InsufficientRCError) Error() string {
+	return fmt.Sprintf("account %s has %d RC but the transaction needs an estimated %d", e.Account, e.Available, e.Required)
+}
+
+// chainInt decodes integers that hived renders either as JSON numbers or, once
+// they no longer fit in 32 bits, as strings
+type chainInt big.Int
+
+func (i *chainInt) UnmarshalJSON(b []byte) error {
+	s := strings.Trim(string(b), `"`)
+	if _, ok := (*big.Int)(i).SetString(s, 10); !ok {
+		return errors.New("invalid integer: " + string(b))
+	}
+	return nil
+}
+
+func (i *chainInt) big() *big.Int {
+	return (*big.Int)(i)
+}
+
+type RCAccount struct {
+	Account string
+	// Mana is the RC mana as of LastUpdateTime, before regeneration
+	Mana           int64
+	LastUpdateTime int64
+	MaxRC          int64
+}
+
+// CurrentMana applies regeneration since the last update to the account's mana
+func (a RCAccount) CurrentMana(now time.Time) int64 {
+	elapsed := now.Unix() - a.LastUpdateTime
+	if elapsed <= 0 {
+		return a.Mana
+	}
+
+	regen := new(big.Int).Mul(big.NewInt(a.MaxRC), big.NewInt(elapsed))
+	regen.Div(regen, big.NewInt(int64(rcRegenTime/time.Second)))
+	mana := regen.Add(regen, big.NewInt(a.Mana))
+	if mana.Cmp(big.NewInt(a.MaxRC)) > 0 {
+		return a.MaxRC
+	}
+	return mana.Int64()
+}
+
+type findRCAccountsQueryParams struct {
+	Accounts []string `json:"accounts"`
+}
+
+func (h *HiveRpcNode) FindRCAccounts(accounts []string) ([]RCAccount, error) {
+	query := hrpcQuery{method: "rc_api.find_rc_accounts", params: findRCAccountsQueryParams{Accounts: accounts}}
+	res, err := h.rpcExec(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		RCAccounts []struct {
+			Account   string `json:"account"`
+			RCManabar struct {
+				CurrentMana    chainInt `json:"current_mana"`
+				LastUpdateTime int64    `json:"last_update_time"`
+			} `json:"rc_manabar"`
+			MaxRC chainInt `json:"max_rc"`
+		} `json:"rc_accounts"`
+	}
+	err = json.Unmarshal(res, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	var rcAccounts []RCAccount
+	for _, acc := range resp.RCAccounts {
+		rcAccounts = append(rcAccounts, RCAccount{
+			Account:        acc.Account,
+			Mana:           acc.RCManabar.CurrentMana.big().Int64(),
+			LastUpdateTime: acc.RCManabar.LastUpdateTime,
+			MaxRC:          acc.MaxRC.big().Int64(),
+		})
+	}
+	return rcAccounts, nil
+}
+
+type rcPriceCurveParams struct {
+	CoeffA chainInt `json:"coeff_a"`
+	CoeffB chainInt `json:"coeff_b"`
+	Shift  uint     `json:"shift"`
+}
+
+type rcResourceParams struct {
+	ResourceParams map[string]struct {
+		ResourceDynamicsParams struct {
+			ResourceUnit int64 `json:"resource_unit"`
+		} `json:"resource_dynamics_params"`
+		PriceCurveParams rcPriceCurveParams `json:"price_curve_params"`
+	} `json:"resource_params"`
+	SizeInfo struct {
+		ResourceStateBytes    map[string]int64 `json:"resource_state_bytes"`
+		ResourceExecutionTime map[string]int64 `json:"resource_execution_time"`
+	} `json:"size_info"`
+}
+
+type rcResourcePool struct {
+	ResourcePool map[string]struct {
+		Pool chainInt `json:"pool"`
+	} `json:"resource_pool"`
+}
+
+// EstimateRCCost estimates the resource credits a single signature transaction
+// carrying ops will cost, following the rc plugin's pricing: each resource the
+// transaction consumes is priced on a curve against the resource's current pool.
+func (h *HiveRpcNode) EstimateRCCost(ops []HiveOperation) (int64, error) {
+	opsB, err := serializeOps(ops)
+	if err != nil {
+		return 0, err
+	}
+	txSize := int64(len(opsB) + txOverheadSize)
+
+	var params rcResourceParams
+	err = h.rpcExecInto(hrpcQuery{method: "rc_api.get_resource_params", params: struct{}{}}, &params)
+	if err != nil {
+		return 0, err
+	}
+
+	var pool rcResourcePool
+	err = h.rpcExecInto(hrpcQuery{method: "rc_api.get_resource_pool", params: struct{}{}}, &pool)
+	if err != nil {
+		return 0, err
+	}
+
+	props, err := h.getGlobalProps()
+	if err != nil {
+		return 0, err
+	}
+	totalVests, err := assetSatoshis(props.TotalVestingShares)
+	if err != nil {
+		return 0, err
+	}
+	rcRegen := totalVests.Div(totalVests, big.NewInt(int64(rcRegenTime/blockInterval)))
+
+	var cost int64
+	for resource, count := range rcResourceCounts(ops, txSize, params) {
+		resourceParams, ok := params.ResourceParams[resource]
+		if !ok {
+			continue
+		}
+		resourcePool := pool.ResourcePool[resource].Pool
+		count *= resourceParams.ResourceDynamicsParams.ResourceUnit
+		cost += rcResourceCost(resourceParams.PriceCurveParams, resourcePool.big(), count, rcRegen)
+	}
+	return cost, nil
+}
+
+// rcResourceCounts estimates how much of each resource a transaction uses
+func rcResourceCounts(ops []HiveOperation, txSize int64, params rcResourceParams) map[string]int64 {
+	stateBytes := params.SizeInfo.ResourceStateBytes
+	execTime := params.SizeInfo.ResourceExecutionTime
+
+	counts := map[string]int64{
+		"resource_history_bytes": txSize,
+		"resource_state_bytes":   stateBytes["transaction_object_base_size"] + stateBytes["transaction_object_byte_size"]*txSize,
+	}
+	for _, op := range ops {
+		name := op.OpName()
+		counts["resource_execution_time"] += execTime[name+"_operation_exec_time"]
+
+		switch name {
+		case "vote":
+			counts["resource_state_bytes"] += stateBytes["comment_vote_object_base_size"]
+		case "limit_order_create", "limit_order_create2", "limit_order_cancel", "convert", "collateralized_convert":
+			counts["resource_market_bytes"] = txSize
+		case "account_create", "account_create_with_delegation", "claim_account":
+			counts["resource_new_accounts"]++
+		}
+	}
+	return counts
+}
+
+// rcResourceCost mirrors hived's compute_rc_cost_of_resource
+func rcResourceCost(curve rcPriceCurveParams, pool *big.Int, count int64, rcRegen *big.Int) int64 {
+	if count <= 0 {
+		return 0
+	}
+
+	num := new(big.Int).Mul(rcRegen, curve.CoeffA.big())
+	num.Rsh(num, curve.Shift)
+	num.Add(num, big.NewInt(1))
+	num.Mul(num, big.NewInt(count))
+
+	denom := new(big.Int).Set(curve.CoeffB.big())
+	if pool != nil && pool.Sign() > 0 {
+		denom.Add(denom, pool)
+	}
+
+	return num.Div(num, denom).Int64() + 1
+}
+
+// assetSatoshis converts an asset string such as "123.456789 VESTS" to its
+// integer amount in the asset's smallest unit
+func assetSatoshis(asset string) (*big.Int, error) {
+	parts := strings.Split(asset, " ")
+	amount, ok := new(big.Int).SetString(strings.Replace(parts[0], ".", "", 1), 10)
+	if !ok {
+		return nil, errors.New("invalid asset format: " + asset)
+	}
+	return amount, nil
+}
+
+// checkRC refuses ops whose estimated cost exceeds the paying account's RC
+func (h *HiveRpcNode) checkRC(ops []HiveOperation) error {
+	payer, err := rcPayer(ops)
+	if err != nil {
+		return err
+	}
+
+	cost, err := h.EstimateRCCost(ops)
+	if err != nil {
+		return err
+	}
+
+	rcAccounts, err := h.FindRCAccounts([]string{payer})
+	if err != nil {
+		return err
+	}
+	if len(rcAccounts) == 0 {
+		return errors.New("RC account not found: " + payer)
+	}
+
+	available := rcAccounts[0].CurrentMana(time.Now())
+	if available < cost {
+		return &InsufficientRCError{Account: payer, Available: available, Required: cost}
+	}
+	return nil
+}
+
+// rcPayer returns the account hived charges RC to: the first required
+// authority of the first operation
+func rcPayer(ops []HiveOperation) (string, error) {
+	if len(ops) == 0 {
+		return "", errors.New("no operations")
+	}
+
+	auths, err := requiredAuthorities(ops[0])
+	if err != nil {
+		return "", err
+	}
+	if len(auths) == 0 {
+		return "", errors.New("cannot determine the RC payer of operation " + ops[0].OpName())
+	}
+	return auths[0].account, nil
+}
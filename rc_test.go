@@ -0,0 +1,90 @@
+package hivego
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestCurrentManaRCAccount(t *testing.T) {
+	acc := RCAccount{Mana: 1000, LastUpdateTime: 0, MaxRC: 432000}
+
+	got := acc.CurrentMana(time.Unix(100, 0))
+	if got != 1100 {
+		t.Error("Expected", 1100, "got", got)
+	}
+
+	got = acc.CurrentMana(time.Unix(1000000, 0))
+	if got != 432000 {
+		t.Error("Expected", 432000, "got", got)
+	}
+}
+
+func TestRCResourceCost(t *testing.T) {
+	var curve rcPriceCurveParams
+	curve.CoeffA.big().SetInt64(4)
+	curve.CoeffB.big().SetInt64(10)
+	curve.Shift = 1
+
+	got := rcResourceCost(curve, big.NewInt(90), 2, big.NewInt(100))
+	if got != 5 {
+		t.Error("Expected", 5, "got", got)
+	}
+
+	if got = rcResourceCost(curve, big.NewInt(90), 0, big.NewInt(100)); got != 0 {
+		t.Error("Expected", 0, "got", got)
+	}
+}
+
+func TestBroadcastCheckRC(t *testing.T) {
+	node, h := newTestNode(t, WithCheckRC())
+	node.handle("rc_api.get_resource_params", func(json.RawMessage) (interface{}, error) {
+		return json.RawMessage(`{"resource_params":{"resource_history_bytes":{"resource_dynamics_params":{"resource_unit":1},"price_curve_params":{"coeff_a":"1","coeff_b":"1","shift":0}}}}`), nil
+	})
+	node.handle("rc_api.get_resource_pool", func(json.RawMessage) (interface{}, error) {
+		return json.RawMessage(`{"resource_pool":{"resource_history_bytes":{"pool":"0"}}}`), nil
+	})
+	setMana := func(mana string) {
+		node.handle("rc_api.find_rc_accounts", func(json.RawMessage) (interface{}, error) {
+			return json.RawMessage(`{"rc_accounts":[{"account":"xeroc","rc_manabar":{"current_mana":"` + mana + `","last_update_time":9999999999},"max_rc":"100000000000000"}]}`), nil
+		})
+	}
+	setMana("10")
+
+	signer, _ := NewKeySigner("5JuMt237G3m3BaT7zH4YdoycUtbw4AEPy6DLdCrKAnFGAtXyQ1W")
+	_, err := h.Broadcast([]HiveOperation{getTestVoteOp()}, signer)
+
+	var rcErr *InsufficientRCError
+	if !errors.As(err, &rcErr) || rcErr.Account != "xeroc" || rcErr.Available != 10 {
+		t.Fatal("Expected InsufficientRCError for xeroc, got", err)
+	}
+	if len(node.broadcastOps()) != 0 {
+		t.Error("Expected nothing to be broadcast")
+	}
+
+	setMana("100000000000000")
+	if _, err = h.Broadcast([]HiveOperation{getTestVoteOp()}, signer); err != nil {
+		t.Error("Expected broadcast to succeed, got", err)
+	}
+}
+
+func TestRCPayer(t *testing.T) {
+	for _, tc := range []struct {
+		op       HiveOperation
+		expected string
+	}{
+		{getTestVoteOp(), "xeroc"},
+		{NewCustomJsonOp([]string{"alice"}, []string{"bob"}, "test", "{}"), "alice"},
+		{NewCustomJsonOp(nil, []string{"bob"}, "test", "{}"), "bob"},
+	} {
+		if payer, err := rcPayer([]HiveOperation{tc.op}); err != nil || payer != tc.expected {
+			t.Error("Expected", tc.expected, "got", payer, err)
+		}
+	}
+
+	if _, err := rcPayer([]HiveOperation{NewCustomJsonOp(nil, nil, "test", "{}")}); err == nil {
+		t.Error("Expected error for an operation without authorities")
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"math"
 	"strconv"
 	"strings"
@@ -69,7 +68,7 @@ func appendVStringArray(a []string, b *bytes.Buffer) *bytes.Buffer {
 	return b
 }
 
-func appendVAsset(asset string, b *bytes.Buffer) error {
+func appendVAsset(asset string, net *NetworkConfig, b *bytes.Buffer) error {
 	parts := strings.Split(asset, " ")
 	if len(parts) != 2 {
 		return errors.New("invalid asset format: " + asset)
@@ -77,19 +76,11 @@ func appendVAsset(asset string, b *bytes.Buffer) error {
 
 	amountStr, symbol := parts[0], parts[1]
 
-	// all tokens have precision 3 except for VESTS
-	precision := 3
-
-	if symbol == "VESTS" {
-		precision = 6
-	}
-
-	// convert to their old names for compatibility
-	switch symbol {
-	case "HIVE":
-		symbol = "STEEM"
-	case "HBD":
-		symbol = "SBD"
+	// map to the symbol the network serializes, e.g. HIVE is still written as
+	// STEEM on mainnet
+	symbol, precision, ok := net.serializedSymbol(symbol)
+	if !ok {
+		return errors.New("unknown asset symbol: " + parts[1])
 	}
 
 	// convert to float and multiply by 10^precision
@@ -123,7 +114,7 @@ func appendVAsset(asset string, b *bytes.Buffer) error {
 	return nil
 }
 
-func serializeTx(tx hiveTransaction) ([]byte, error) {
+func serializeTx(tx HiveTransaction) ([]byte, error) {
 	var buf bytes.Buffer
 	buf.Write(refBlockNumB(tx.RefBlockNum))
 	buf.Write(refBlockPrefixB(tx.RefBlockPrefix))
@@ -184,19 +175,19 @@ func (o claimRewardOperation) SerializeOp() ([]byte, error) {
 	var claimBuf bytes.Buffer
 	claimBuf.Write([]byte{opIdB(o.opText)})
 	appendVString(o.Account, &claimBuf)
-	err := appendVAsset(o.RewardHIVE, &claimBuf)
+	err := appendVAsset(o.RewardHIVE, o.net, &claimBuf)
 
 	if err != nil {
 		return nil, err
 	}
 
-	err = appendVAsset(o.RewardHBD, &claimBuf)
+	err = appendVAsset(o.RewardHBD, o.net, &claimBuf)
 
 	if err != nil {
 		return nil, err
 	}
 
-	err = appendVAsset(o.RewardVests, &claimBuf)
+	err = appendVAsset(o.RewardVests, o.net, &claimBuf)
 
 	if err != nil {
 		return nil, err
@@ -210,7 +201,9 @@ func (o transferOperation) SerializeOp() ([]byte, error) {
 	transferBuf.Write([]byte{opIdB(o.opText)})
 	appendVString(o.From, &transferBuf)
 	appendVString(o.To, &transferBuf)
-	appendVAsset(o.Amount, &transferBuf)
+	if err := appendVAsset(o.Amount, o.net, &transferBuf); err != nil {
+		return nil, err
+	}
 	appendVString(o.Memo, &transferBuf)
 
 	return transferBuf.Bytes(), nil
@@ -226,22 +219,18 @@ func (a accountUpdateOperation) SerializeOp() ([]byte, error) {
 	appendVString(a.Account, &buf)
 
 	// serialize optional authorities (owner, active, posting)
-	// TODO: THIS IS UNTESTED
-	appendOptionalAuthority(a.Owner, &buf)
-	appendOptionalAuthority(a.Active, &buf)
-	appendOptionalAuthority(a.Posting, &buf)
-
-	// memo key
-	//
-	// The memo key is kept as a string argument for the sake of simplicity and
-	// because it's intuative to the user. However, it must be serialized as a
-	// public key. We decode the public key and compressed to 33 bytes to actually
-	// be used.
-	pubKey, err := DecodePublicKey(a.MemoKey)
+	for _, auth := range []*Auths{a.Owner, a.Active, a.Posting} {
+		if err := appendOptionalAuthority(auth, a.net, &buf); err != nil {
+			return nil, err
+		}
+	}
+
+	// memo key, as a 33 byte compressed public key
+	memoKey, err := a.MemoKey.bytesFor(a.net)
 	if err != nil {
 		return nil, err
 	}
-	buf.Write(pubKey.SerializeCompressed())
+	buf.Write(memoKey)
 
 	// JSON metadata
 	appendVString(a.JsonMetadata, &buf)
@@ -249,14 +238,13 @@ func (a accountUpdateOperation) SerializeOp() ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
-// todo: UNTESTED
-func appendOptionalAuthority(auth *Auths, buf *bytes.Buffer) {
+func appendOptionalAuthority(auth *Auths, net *NetworkConfig, buf *bytes.Buffer) error {
 	if auth != nil {
 		buf.WriteByte(1) // field is present, so we prepend a 1
-		serializeAuthority(*auth, buf)
-	} else {
-		buf.WriteByte(0) // field is absent, so we write a 0
+		return serializeAuthority(*auth, net, buf)
 	}
+	buf.WriteByte(0) // field is absent, so we write a 0
+	return nil
 }
 
 // todo: UNTESTED
@@ -281,42 +269,81 @@ func WriteVarint(w io.Writer, x int64) error {
 	return nil
 }
 
-// todo: UNTESTED
-func serializeAuthority(auth Auths, buf *bytes.Buffer) {
+// serializeAuthority requires account_auths sorted by account name and
+// key_auths sorted by compressed public key, without duplicates. hived keeps
+// both in sorted maps, so it would serialize any other order differently from
+// what was signed.
+func serializeAuthority(auth Auths, net *NetworkConfig, buf *bytes.Buffer) error {
 	// write weight_threshold
 	err := binary.Write(buf, binary.LittleEndian, uint32(auth.WeightThreshold))
 	if err != nil {
-		fmt.Printf("Error writing weight_threshold: %v\n", err)
-		return
+		return fmt.Errorf("error writing weight_threshold: %w", err)
 	}
 
 	// write account_auths
 	err = WriteUvarint(buf, uint64(len(auth.AccountAuths)))
 	if err != nil {
-		log.Printf("error writing account_auths length: %v\n", err)
-		return
+		return fmt.Errorf("error writing account_auths length: %w", err)
 	}
-	for _, accountAuth := range auth.AccountAuths {
-		appendVString(accountAuth[0].(string), buf)
-		err = binary.Write(buf, binary.LittleEndian, uint16(accountAuth[1].(int)))
+	for i, accountAuth := range auth.AccountAuths {
+		account, ok := accountAuth[0].(string)
+		if !ok {
+			return fmt.Errorf("invalid account_auth account: %v", accountAuth[0])
+		}
+		if i > 0 && account <= auth.AccountAuths[i-1][0].(string) {
+			return fmt.Errorf("account_auths must be sorted by account without duplicates: %s", account)
+		}
+		weight, err := authWeight(accountAuth[1])
+		if err != nil {
+			return err
+		}
+		appendVString(account, buf)
+		err = binary.Write(buf, binary.LittleEndian, weight)
 		if err != nil {
-			log.Printf("error writing account_auth weight: %v\n", err)
-			return
+			return fmt.Errorf("error writing account_auth weight: %w", err)
 		}
 	}
 
-	// write key_auths
+	// write key_auths, with each key as a compressed public key
 	err = WriteUvarint(buf, uint64(len(auth.KeyAuths)))
 	if err != nil {
-		log.Printf("error writing key_auths length: %v\n", err)
-		return
+		return fmt.Errorf("error writing key_auths length: %w", err)
 	}
+	var prevKey []byte
 	for _, keyAuth := range auth.KeyAuths {
-		appendVString(keyAuth[0].(string), buf)
-		err = binary.Write(buf, binary.LittleEndian, uint16(keyAuth[1].(int)))
+		keyStr, ok := keyAuth[0].(string)
+		if !ok {
+			return fmt.Errorf("invalid key_auth key: %v", keyAuth[0])
+		}
+		pubKey, err := net.DecodePublicKey(keyStr)
 		if err != nil {
-			log.Printf("error writing key_auth weight: %v\n", err)
-			return
+			return err
+		}
+		weight, err := authWeight(keyAuth[1])
+		if err != nil {
+			return err
+		}
+		key := pubKey.SerializeCompressed()
+		if prevKey != nil && bytes.Compare(key, prevKey) <= 0 {
+			return fmt.Errorf("key_auths must be sorted by key without duplicates: %s", keyStr)
 		}
+		prevKey = key
+		buf.Write(key)
+		err = binary.Write(buf, binary.LittleEndian, weight)
+		if err != nil {
+			return fmt.Errorf("error writing key_auth weight: %w", err)
+		}
+	}
+	return nil
+}
+
+// authWeight accepts weights both as set in code (int) and as decoded from JSON (float64)
+func authWeight(w interface{}) (uint16, error) {
+	switch v := w.(type) {
+	case int:
+		return uint16(v), nil
+	case float64:
+		return uint16(v), nil
 	}
+	return 0, fmt.Errorf("invalid authority weight: %v", w)
 }
@@ -5,54 +5,94 @@ import (
 	"crypto/sha256"
 	"encoding/binary"
 	"encoding/hex"
-	"encoding/json"
 	"errors"
+	"fmt"
+	"math/big"
 	"time"
 
 	"github.com/decred/base58"
 	"github.com/decred/dcrd/dcrec/secp256k1/v2"
 )
 
+const (
+	defaultTxExpiration = 30 * time.Second
+	maxTxExpiration     = time.Hour
+)
+
 type signingDataFromChain struct {
 	refBlockNum    uint16
 	refBlockPrefix uint32
 	expiration     string
+	headTime       time.Time
 }
 
 func (h *HiveRpcNode) getSigningData() (signingDataFromChain, error) {
-	propsB, err := h.GetDynamicGlobalProps()
+	expiration, err := txExpiration(h.txExpiration)
 	if err != nil {
 		return signingDataFromChain{}, err
 	}
 
-	var props globalProps
-	err = json.Unmarshal(propsB, &props)
+	props, err := h.getGlobalProps()
 	if err != nil {
 		return signingDataFromChain{}, err
 	}
 
-	refBlockNum := uint16(props.HeadBlockNumber & 0xffff)
-	hbidB, err := hex.DecodeString(props.HeadBlockId)
+	refBlock, refBlockId := props.HeadBlockNumber, props.HeadBlockId
+	if h.refIrreversible {
+		block, err := h.GetBlock(props.LastIrreversibleBlockNum)
+		if err != nil {
+			return signingDataFromChain{}, err
+		}
+		if block.BlockID == "" {
+			return signingDataFromChain{}, fmt.Errorf("last irreversible block %d not found", props.LastIrreversibleBlockNum)
+		}
+		refBlock, refBlockId = props.LastIrreversibleBlockNum, block.BlockID
+	}
+
+	refBlockNum, refBlockPrefix, err := tapos(refBlock, refBlockId)
 	if err != nil {
 		return signingDataFromChain{}, err
 	}
-	refBlockPrefix := binary.LittleEndian.Uint32(hbidB[4:])
 
-	exp, err := time.Parse("2006-01-02T15:04:05", props.Time)
+	headTime, err := time.Parse("2006-01-02T15:04:05", props.Time)
 	if err != nil {
 		return signingDataFromChain{}, err
 	}
-	exp = exp.Add(30 * time.Second)
+	exp := headTime.Add(expiration)
 	expStr := exp.Format("2006-01-02T15:04:05")
 
-	signingData := signingDataFromChain{refBlockNum, refBlockPrefix, expStr}
+	signingData := signingDataFromChain{refBlockNum, refBlockPrefix, expStr, headTime}
 
 	return signingData, nil
 }
 
-func hashTxForSig(tx []byte) []byte {
+// txExpiration resolves a configured expiration, applying the default and
+// rejecting windows the chain would not accept
+func txExpiration(d time.Duration) (time.Duration, error) {
+	if d == 0 {
+		return defaultTxExpiration, nil
+	}
+	if d < 0 || d > maxTxExpiration {
+		return 0, fmt.Errorf("transaction expiration %s is outside the allowed range (0, %s]", d, maxTxExpiration)
+	}
+	return d, nil
+}
+
+// tapos returns the ref_block_num and ref_block_prefix for the given block
+func tapos(blockNum int, blockId string) (uint16, uint32, error) {
+	idB, err := hex.DecodeString(blockId)
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(idB) < 8 {
+		return 0, 0, errors.New("invalid block id: " + blockId)
+	}
+	return uint16(blockNum & 0xffff), binary.LittleEndian.Uint32(idB[4:]), nil
+}
+
+func hashTxForSig(chainId []byte, tx []byte) []byte {
 	var message bytes.Buffer
-	message.Write(getHiveChainId())
+	message.Write(chainId)
 	message.Write(tx)
 
 	digest := sha256.New()
@@ -69,14 +109,211 @@ func hashTx(tx []byte) []byte {
 	return digest.Sum(nil)
 }
 
-func SignDigest(digest []byte, wif *string) ([]byte, error) {
-	keyPair, err := KeyPairFromWif(*wif)
+// Signer produces the signatures on broadcast transactions. Implementations
+// decide where the private keys live; hivego only ever sees public keys and
+// signatures.
+type Signer interface {
+	// PublicKeys returns the public keys of the keys the signer signs with
+	PublicKeys() []*secp256k1.PublicKey
+	// SignDigest returns a canonical compact signature of digest from each key
+	SignDigest(digest []byte) ([][]byte, error)
+}
+
+// KeySigner is a Signer holding decoded private keys in memory, in
+// PrivateKey buffers that Destroy zeroes
+type KeySigner struct {
+	keys []*PrivateKey
+}
+
+// NewKeySigner decodes each WIF once and signs with all of the resulting keys
+func NewKeySigner(wifs ...string) (*KeySigner, error) {
+	var keys []*PrivateKey
+	for _, wif := range wifs {
+		key, err := PrivateKeyFromWif(wif)
+		if err != nil {
+			for _, key := range keys {
+				key.Destroy()
+			}
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return NewKeySignerFromPrivateKeys(keys...), nil
+}
+
+// NewKeySignerFromKeyPairs copies each key pair's private key into a
+// PrivateKey, failing on keys outside the curve order
+func NewKeySignerFromKeyPairs(keyPairs ...*KeyPair) (*KeySigner, error) {
+	var keys []*PrivateKey
+	for _, keyPair := range keyPairs {
+		secret := keyPair.PrivateKey.Serialize()
+		key, err := PrivateKeyFromBytes(secret)
+		zero(secret)
+		if err != nil {
+			for _, key := range keys {
+				key.Destroy()
+			}
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return NewKeySignerFromPrivateKeys(keys...), nil
+}
+
+// NewKeySignerFromPrivateKeys signs with keys, which Destroy then zeroes
+func NewKeySignerFromPrivateKeys(keys ...*PrivateKey) *KeySigner {
+	return &KeySigner{keys: keys}
+}
+
+func (s *KeySigner) PublicKeys() []*secp256k1.PublicKey {
+	var pubKeys []*secp256k1.PublicKey
+	for _, key := range s.keys {
+		pubKeys = append(pubKeys, key.PublicKey())
+	}
+	return pubKeys
+}
+
+func (s *KeySigner) SignDigest(digest []byte) ([][]byte, error) {
+	var sigs [][]byte
+	for _, key := range s.keys {
+		sig, err := key.Sign(digest)
+		if err != nil {
+			return nil, err
+		}
+		sigs = append(sigs, sig)
+	}
+	return sigs, nil
+}
+
+// Destroy zeroes the signer's keys
+func (s *KeySigner) Destroy() {
+	for _, key := range s.keys {
+		key.Destroy()
+	}
+}
 
+// maxSigningAttempts bounds the search for a canonical signature. About half
+// of all attempts fail, mostly on the high bit of R, so this is never reached
+// in practice.
+const maxSigningAttempts = 256
+
+// SignDigest signs digest with the key behind wif, producing a compact
+// signature that hived accepts as canonical. The decoded key is zeroed
+// afterwards; a PrivateKey avoids decoding it on every call.
+func SignDigest(digest []byte, wif *string) ([]byte, error) {
+	key, err := PrivateKeyFromWif(*wif)
 	if err != nil {
 		return nil, err
 	}
+	defer key.Destroy()
+
+	return key.Sign(digest)
+}
+
+// signCanonical produces a canonical compact signature. The first attempt uses
+// a plain RFC 6979 nonce; like the reference implementations, later attempts
+// mix sha256(digest || attempt) into the nonce until the result is canonical.
+func signCanonical(key *secp256k1.PrivateKey, digest []byte) ([]byte, error) {
+	if len(digest) != sha256.Size {
+		return nil, errors.New("digest must be 32 bytes")
+	}
+
+	for attempt := 0; attempt < maxSigningAttempts; attempt++ {
+		var extra []byte
+		if attempt > 0 {
+			extraHash := sha256.Sum256(append(append([]byte{}, digest...), byte(attempt)))
+			extra = extraHash[:]
+		}
+
+		sig, err := signCompact(key, digest, extra)
+		if err != nil {
+			return nil, err
+		}
+		if isCanonicalSignature(sig) {
+			return sig, nil
+		}
+	}
 
-	return secp256k1.SignCompact(keyPair.PrivateKey, digest, true)
+	return nil, errors.New("failed to produce a canonical signature")
+}
+
+// signCompact signs digest using a deterministic nonce derived with the given
+// extra data, returning a compact signature for a compressed public key
+func signCompact(key *secp256k1.PrivateKey, digest []byte, extra []byte) ([]byte, error) {
+	curve := secp256k1.S256()
+	n := curve.Params().N
+
+	// the nonce and anything combining it or the key with public values
+	// would reveal the key, so they are wiped like the key itself
+	k := secp256k1.NonceRFC6979(key.D, digest, extra, nil)
+	defer wipeInt(k)
+	kB := k.Bytes()
+	defer zero(kB)
+	r, _ := curve.ScalarBaseMult(kB)
+	r.Mod(r, n)
+	if r.Sign() == 0 {
+		return nil, errors.New("calculated R is zero")
+	}
+
+	kInv := new(big.Int).ModInverse(k, n)
+	defer wipeInt(kInv)
+	s := new(big.Int).Mul(key.D, r)
+	defer wipeInt(s)
+	s.Add(s, new(big.Int).SetBytes(digest))
+	s.Mul(s, kInv)
+	s.Mod(s, n)
+	if s.Cmp(new(big.Int).Rsh(n, 1)) == 1 {
+		s.Sub(n, s)
+	}
+	if s.Sign() == 0 {
+		return nil, errors.New("calculated S is zero")
+	}
+
+	sig := make([]byte, 65)
+	r.FillBytes(sig[1:33])
+	s.FillBytes(sig[33:])
+
+	pubKey := key.PubKey()
+	for i := byte(0); i < 4; i++ {
+		sig[0] = 27 + 4 + i
+		recovered, _, err := secp256k1.RecoverCompact(sig, digest)
+		if err == nil && recovered.IsEqual(pubKey) {
+			return sig, nil
+		}
+	}
+
+	return nil, errors.New("no valid solution for pubkey found")
+}
+
+// isCanonicalSignature applies hived's canonical signature rule to a compact
+// signature: neither R nor S may have the high bit set or carry a redundant
+// leading zero byte
+func isCanonicalSignature(sig []byte) bool {
+	return len(sig) == 65 &&
+		sig[1]&0x80 == 0 &&
+		!(sig[1] == 0 && sig[2]&0x80 == 0) &&
+		sig[33]&0x80 == 0 &&
+		!(sig[33] == 0 && sig[34]&0x80 == 0)
+}
+
+// RecoverPublicKey recovers the public key that produced a compact signature
+// of digest. Like hived, it rejects signatures that are not canonical.
+func RecoverPublicKey(digest []byte, sig []byte) (*secp256k1.PublicKey, error) {
+	if len(sig) != 65 {
+		return nil, errors.New("invalid compact signature length")
+	}
+	if sig[0] < 27 || sig[0] > 34 {
+		return nil, errors.New("invalid compact signature recovery id")
+	}
+	if !isCanonicalSignature(sig) {
+		return nil, errors.New("signature is not canonical")
+	}
+
+	pubKey, _, err := secp256k1.RecoverCompact(sig, digest)
+	if err != nil {
+		return nil, err
+	}
+	return pubKey, nil
 }
 
 func GphBase58CheckDecode(input string) ([]byte, [1]byte, error) {
@@ -95,6 +332,14 @@ func GphBase58CheckDecode(input string) ([]byte, [1]byte, error) {
 	return payload, version, nil
 }
 
+// GphBase58CheckEncode is the inverse of GphBase58CheckDecode
+func GphBase58CheckEncode(payload []byte, version byte) string {
+	data := append([]byte{version}, payload...)
+	defer zero(data)
+	sum := checksum(data)
+	return base58.Encode(append(data, sum[:]...))
+}
+
 func checksum(input []byte) [4]byte {
 	var calculatedChecksum [4]byte
 	intermediateHash := sha256.Sum256(input)
@@ -2,11 +2,21 @@ package hivego
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"os"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v2"
 )
 
 func TestHashTxForSig(t *testing.T) {
-	got := hashTxForSig([]byte{189, 140, 95, 226, 111, 69, 241, 121, 168, 87, 1, 0, 5, 120, 101, 114, 111, 99, 5, 120, 101, 114, 111, 99, 6, 112, 105, 115, 116, 111, 110, 16, 39, 0})
+	chainId, _ := MainnetConfig.chainId()
+	got := hashTxForSig(chainId, []byte{189, 140, 95, 226, 111, 69, 241, 121, 168, 87, 1, 0, 5, 120, 101, 114, 111, 99, 5, 120, 101, 114, 111, 99, 6, 112, 105, 115, 116, 111, 110, 16, 39, 0})
 	expected := []byte{14, 93, 189, 151, 159, 79, 35, 135, 197, 213, 161, 182, 73, 239, 6, 88, 150, 48, 250, 247, 192, 101, 222, 160, 218, 142, 89, 43, 3, 218, 6, 188}
 
 	if !bytes.Equal(got, expected) {
@@ -26,7 +36,9 @@ func TestHashTx(t *testing.T) {
 func TestSignDigest(t *testing.T) {
 	wif := "5JuMt237G3m3BaT7zH4YdoycUtbw4AEPy6DLdCrKAnFGAtXyQ1W"
 	got, _ := SignDigest([]byte{18, 22, 77, 206, 229, 24, 103, 76, 88, 110, 106, 97, 208, 134, 35, 196, 73, 128, 227, 38, 201, 129, 108, 80, 35, 200, 184, 136, 15, 114, 61, 107}, &wif)
-	expected := []byte{31, 135, 178, 255, 150, 145, 101, 147, 159, 43, 208, 208, 212, 141, 167, 79, 124, 202, 216, 106, 84, 92, 126, 124, 217, 124, 39, 47, 105, 76, 20, 113, 229, 2, 5, 110, 116, 181, 214, 32, 111, 134, 253, 231, 100, 158, 241, 102, 238, 32, 213, 22, 155, 115, 226, 172, 85, 229, 183, 99, 0, 155, 252, 229, 186}
+	// the plain RFC 6979 signature of this digest has the high bit of R set, so
+	// a canonical signature is found with extra nonce data
+	expected := []byte{31, 80, 23, 59, 208, 204, 65, 32, 249, 92, 117, 130, 217, 148, 38, 58, 21, 251, 59, 24, 56, 4, 199, 51, 53, 185, 129, 75, 30, 53, 74, 204, 218, 75, 29, 78, 183, 91, 163, 74, 41, 124, 112, 125, 130, 207, 254, 37, 138, 140, 147, 79, 9, 158, 100, 197, 235, 169, 120, 88, 55, 124, 96, 36, 52}
 	if !bytes.Equal(got, expected) {
 		t.Error("Expected", expected, "got", got)
 	}
@@ -40,3 +52,200 @@ func TestGphBase58CheckDecode(t *testing.T) {
 		t.Error("Expected", expected1, "and", expected2, "got", got1, "and", got2)
 	}
 }
+
+func TestTxExpiration(t *testing.T) {
+	got, err := txExpiration(0)
+	if err != nil || got != defaultTxExpiration {
+		t.Error("Expected", defaultTxExpiration, "got", got, err)
+	}
+
+	got, err = txExpiration(maxTxExpiration)
+	if err != nil || got != maxTxExpiration {
+		t.Error("Expected", maxTxExpiration, "got", got, err)
+	}
+
+	if _, err = txExpiration(maxTxExpiration + time.Second); err == nil {
+		t.Error("Expected error for expiration past the chain maximum")
+	}
+
+	if _, err = txExpiration(-time.Second); err == nil {
+		t.Error("Expected error for negative expiration")
+	}
+}
+
+func TestTapos(t *testing.T) {
+	gotNum, gotPrefix, err := tapos(4463677, "00441c3d5fe26f45af2c94edb1b69dbdd77d8ba0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotNum != 7229 || gotPrefix != 1164960351 {
+		t.Error("Expected", 7229, 1164960351, "got", gotNum, gotPrefix)
+	}
+
+	if _, _, err = tapos(1, "0044"); err == nil {
+		t.Error("Expected error for short block id")
+	}
+}
+
+func TestSignDigestCanonical(t *testing.T) {
+	wif := "5JuMt237G3m3BaT7zH4YdoycUtbw4AEPy6DLdCrKAnFGAtXyQ1W"
+	keyPair, _ := KeyPairFromWif(wif)
+
+	for i := 0; i < 1000; i++ {
+		digest := sha256.Sum256([]byte{byte(i), byte(i >> 8)})
+		sig, err := SignDigest(digest[:], &wif)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !isCanonicalSignature(sig) {
+			t.Fatalf("Non-canonical signature %x for digest %x", sig, digest)
+		}
+
+		pubKey, err := RecoverPublicKey(digest[:], sig)
+		if err != nil || !pubKey.IsEqual(keyPair.PublicKey) {
+			t.Fatalf("Signature %x for digest %x does not recover the signing key", sig, digest)
+		}
+	}
+}
+
+func TestIsCanonicalSignature(t *testing.T) {
+	sig := make([]byte, 65)
+	sig[1], sig[33] = 0x7f, 0x7f
+	if !isCanonicalSignature(sig) {
+		t.Error("Expected signature to be canonical")
+	}
+
+	sig[1] = 0x80
+	if isCanonicalSignature(sig) {
+		t.Error("Expected signature with high bit in R to be non-canonical")
+	}
+
+	sig[1], sig[2] = 0, 0x7f
+	if isCanonicalSignature(sig) {
+		t.Error("Expected signature with padded R to be non-canonical")
+	}
+}
+
+func TestRecoverPublicKeyRejectsNonCanonical(t *testing.T) {
+	keyPair, _ := KeyPairFromWif("5JuMt237G3m3BaT7zH4YdoycUtbw4AEPy6DLdCrKAnFGAtXyQ1W")
+	digest := sha256.Sum256([]byte("test"))
+
+	// the same signature with s replaced by n - s is still valid ECDSA, but
+	// has the high bit of S set
+	sig, err := signCanonical(keyPair.PrivateKey, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := secp256k1.S256().N
+	highS := append([]byte{}, sig...)
+	new(big.Int).Sub(n, new(big.Int).SetBytes(sig[33:])).FillBytes(highS[33:])
+	if isCanonicalSignature(highS) {
+		t.Fatal("Expected n - s to give a non-canonical signature")
+	}
+	if _, err := RecoverPublicKey(digest[:], highS); err == nil {
+		t.Error("Expected a signature with a high S to be rejected")
+	}
+
+	// nonces derived from 32 byte extra data, as signCanonical does, give a
+	// signature with the high bit of R set about half of the time
+	found := 0
+	for attempt := byte(1); attempt <= 32; attempt++ {
+		extra := sha256.Sum256(append(append([]byte{}, digest[:]...), attempt))
+		sig, err := signCompact(keyPair.PrivateKey, digest[:], extra[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if isCanonicalSignature(sig) {
+			continue
+		}
+		found++
+		if _, err := RecoverPublicKey(digest[:], sig); err == nil {
+			t.Error("Expected a non-canonical signature to be rejected")
+		}
+	}
+	if found == 0 {
+		t.Fatal("Expected some nonces to give non-canonical signatures")
+	}
+}
+
+func TestKeySigner(t *testing.T) {
+	signer, err := NewKeySigner("5JuMt237G3m3BaT7zH4YdoycUtbw4AEPy6DLdCrKAnFGAtXyQ1W")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	digest := sha256.Sum256([]byte("test"))
+	sigs, err := signer.SignDigest(digest[:])
+	if err != nil || len(sigs) != 1 {
+		t.Fatal("Expected one signature, got", sigs, err)
+	}
+
+	pubKey, err := RecoverPublicKey(digest[:], sigs[0])
+	if err != nil || !pubKey.IsEqual(signer.PublicKeys()[0]) {
+		t.Error("Expected signature to recover the signer's key")
+	}
+
+	if _, err = NewKeySigner("notawif"); err == nil {
+		t.Error("Expected error for invalid WIF")
+	}
+
+	keyPair, _ := KeyPairFromWif("5JuMt237G3m3BaT7zH4YdoycUtbw4AEPy6DLdCrKAnFGAtXyQ1W")
+	fromPairs, err := NewKeySignerFromKeyPairs(keyPair)
+	if err != nil || !fromPairs.PublicKeys()[0].IsEqual(keyPair.PublicKey) {
+		t.Error("Expected a signer for the key pair, got", err)
+	}
+
+	zeroKey, zeroPub := secp256k1.PrivKeyFromBytes(make([]byte, 32))
+	if _, err = NewKeySignerFromKeyPairs(keyPair, &KeyPair{zeroKey, zeroPub}); err == nil {
+		t.Error("Expected error for an invalid key pair")
+	}
+}
+
+// TestExecSignerHelperProcess is run as the external signing program by TestExecSigner
+func TestExecSignerHelperProcess(t *testing.T) {
+	if os.Getenv("HIVEGO_EXEC_SIGNER_HELPER") != "1" {
+		return
+	}
+
+	var req execSignRequest
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		os.Exit(1)
+	}
+	digest, _ := hex.DecodeString(req.Digest)
+	wif := "5JuMt237G3m3BaT7zH4YdoycUtbw4AEPy6DLdCrKAnFGAtXyQ1W"
+	sig, _ := SignDigest(digest, &wif)
+	if os.Getenv("HIVEGO_EXEC_SIGNER_HIGH_S") == "1" {
+		new(big.Int).Sub(secp256k1.S256().N, new(big.Int).SetBytes(sig[33:])).FillBytes(sig[33:])
+	}
+	_ = json.NewEncoder(os.Stdout).Encode(execSignResponse{Signatures: []string{hex.EncodeToString(sig)}})
+	os.Exit(0)
+}
+
+func TestExecSigner(t *testing.T) {
+	t.Setenv("HIVEGO_EXEC_SIGNER_HELPER", "1")
+	keyPair, _ := KeyPairFromWif("5JuMt237G3m3BaT7zH4YdoycUtbw4AEPy6DLdCrKAnFGAtXyQ1W")
+	signer, err := NewExecSigner([]string{*keyPair.GetPublicKeyString()}, os.Args[0], "-test.run=TestExecSignerHelperProcess")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx := getTestVoteTx()
+	if err = tx.Sign(signer); err != nil {
+		t.Fatal(err)
+	}
+	if err = tx.VerifySignatures([]string{*keyPair.GetPublicKeyString()}); err != nil {
+		t.Error("Expected signature to verify, got", err)
+	}
+
+	other, _ := KeyPairFromWif("5JUvJcF6rQvFbZLtDFagreKCYWWcHpHApy7sbRHZ6PeZYNftLh6")
+	signer, _ = NewExecSigner([]string{*other.GetPublicKeyString()}, os.Args[0], "-test.run=TestExecSignerHelperProcess")
+	if err = tx.Sign(signer); err == nil {
+		t.Error("Expected a signature from the wrong key to be rejected")
+	}
+
+	t.Setenv("HIVEGO_EXEC_SIGNER_HIGH_S", "1")
+	signer, _ = NewExecSigner([]string{*keyPair.GetPublicKeyString()}, os.Args[0], "-test.run=TestExecSignerHelperProcess")
+	if err = tx.Sign(signer); err == nil || !strings.Contains(err.Error(), "non-canonical") {
+		t.Error("Expected a non-canonical signature to be reported, got", err)
+	}
+}
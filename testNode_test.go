@@ -0,0 +1,119 @@
+package hivego
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testNode is a minimal JSON-RPC server standing in for a hived node
+type testNode struct {
+	mu             sync.Mutex
+	broadcasts     [][]interface{}
+	broadcastTimes []time.Time
+	handlers       map[string]func(params json.RawMessage) (interface{}, error)
+	url            string
+}
+
+func newTestNode(t *testing.T, opts ...HiveRpcOption) (*testNode, *HiveRpcNode) {
+	node := &testNode{handlers: map[string]func(json.RawMessage) (interface{}, error){}}
+	node.handle("condenser_api.get_dynamic_global_properties", func(json.RawMessage) (interface{}, error) {
+		return globalProps{
+			HeadBlockNumber:          4463677,
+			HeadBlockId:              "00441c3d5fe26f45af2c94edb1b69dbdd77d8ba0",
+			LastIrreversibleBlockNum: 4463660,
+			Time:                     "2016-08-08T12:23:47",
+			TotalVestingShares:       "432000.000000 VESTS",
+		}, nil
+	})
+	node.handle("condenser_api.broadcast_transaction", func(params json.RawMessage) (interface{}, error) {
+		var txs []map[string]interface{}
+		if err := json.Unmarshal(params, &txs); err != nil {
+			return nil, err
+		}
+		node.mu.Lock()
+		node.broadcasts = append(node.broadcasts, txs[0]["operations"].([]interface{}))
+		node.broadcastTimes = append(node.broadcastTimes, time.Now())
+		node.mu.Unlock()
+		return map[string]interface{}{}, nil
+	})
+
+	server := httptest.NewServer(node)
+	t.Cleanup(server.Close)
+	node.url = server.URL
+	return node, NewHiveRpc(server.URL, opts...)
+}
+
+func (n *testNode) handle(method string, fn func(params json.RawMessage) (interface{}, error)) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.handlers[method] = fn
+}
+
+func (n *testNode) broadcastOps() [][]interface{} {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([][]interface{}{}, n.broadcasts...)
+}
+
+func (n *testNode) broadcastTimestamps() []time.Time {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]time.Time{}, n.broadcastTimes...)
+}
+
+type testRequest struct {
+	Id     int             `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+func (n *testNode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// batches are answered with an array of responses
+	if len(body) > 0 && body[0] == '[' {
+		var reqs []testRequest
+		if err := json.Unmarshal(body, &reqs); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		resps := []map[string]interface{}{}
+		for _, req := range reqs {
+			resps = append(resps, n.respond(req))
+		}
+		_ = json.NewEncoder(w).Encode(resps)
+		return
+	}
+
+	var req testRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(n.respond(req))
+}
+
+func (n *testNode) respond(req testRequest) map[string]interface{} {
+	n.mu.Lock()
+	fn, ok := n.handlers[req.Method]
+	n.mu.Unlock()
+
+	resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.Id}
+	if !ok {
+		resp["error"] = map[string]interface{}{"code": -32601, "message": "method not found: " + req.Method}
+	} else if result, err := fn(req.Params); err != nil {
+		resp["error"] = map[string]interface{}{"code": -32000, "message": err.Error()}
+	} else {
+		resp["result"] = result
+	}
+	return resp
+}
@@ -1,5 +1,14 @@
 package hivego
 
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
 type TransactionQueryParams struct {
 	TransactionId     string `json:"id"`
 	IncludeReversible bool   `json:"include_reversible"`
@@ -7,10 +16,159 @@ type TransactionQueryParams struct {
 
 func (h *HiveRpcNode) GetTransaction(txId string, includeReversible bool) ([]byte, error) {
 	var query = hrpcQuery{method: "account_history_api.get_transaction", params: TransactionQueryParams{TransactionId: txId, IncludeReversible: includeReversible}}
-	endpoint := h.address
-	res, err := h.rpcExec(endpoint, query)
+	res, err := h.rpcExec(query)
 	if err != nil {
 		return nil, err
 	}
 	return res, nil
 }
+
+type transactionInclusion struct {
+	BlockNum       int `json:"block_num"`
+	TransactionNum int `json:"transaction_num"`
+}
+
+// getTransactionInclusion looks up the block a transaction was included in
+func (h *HiveRpcNode) getTransactionInclusion(txId string) (transactionInclusion, error) {
+	res, err := h.GetTransaction(txId, true)
+	if err != nil {
+		return transactionInclusion{}, err
+	}
+
+	var inclusion transactionInclusion
+	err = json.Unmarshal(res, &inclusion)
+	if err != nil {
+		return transactionInclusion{}, err
+	}
+	return inclusion, nil
+}
+
+type TransactionStatus string
+
+const (
+	TxStatusUnknown                 TransactionStatus = "unknown"
+	TxStatusWithinMempool           TransactionStatus = "within_mempool"
+	TxStatusWithinReversibleBlock   TransactionStatus = "within_reversible_block"
+	TxStatusWithinIrreversibleBlock TransactionStatus = "within_irreversible_block"
+	TxStatusExpiredReversible       TransactionStatus = "expired_reversible"
+	TxStatusExpiredIrreversible     TransactionStatus = "expired_irreversible"
+	TxStatusTooOld                  TransactionStatus = "too_old"
+)
+
+// IsFinal reports whether the status can no longer change
+func (s TransactionStatus) IsFinal() bool {
+	switch s {
+	case TxStatusWithinIrreversibleBlock, TxStatusExpiredIrreversible, TxStatusTooOld:
+		return true
+	}
+	return false
+}
+
+type findTransactionQueryParams struct {
+	TransactionId string `json:"transaction_id"`
+	Expiration    string `json:"expiration,omitempty"`
+}
+
+type TransactionStatusResult struct {
+	Status   TransactionStatus `json:"status"`
+	BlockNum int               `json:"block_num"`
+}
+
+// FindTransaction queries transaction_status_api for the state of a
+// transaction. Passing its expiration lets the node tell an expired
+// transaction apart from one it has never seen; it may be left empty.
+func (h *HiveRpcNode) FindTransaction(txId string, expiration string) (*TransactionStatusResult, error) {
+	var query = hrpcQuery{method: "transaction_status_api.find_transaction", params: findTransactionQueryParams{TransactionId: txId, Expiration: expiration}}
+	res, err := h.rpcExec(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var status TransactionStatusResult
+	err = json.Unmarshal(res, &status)
+	if err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// unknownTxGrace is how long after its expiration WatchTransactions keeps
+// polling a transaction the node still does not know
+const unknownTxGrace = 2 * time.Minute
+
+type WatchedTransaction struct {
+	TxId string
+	// Expiration is required, both for the node to tell an expired
+	// transaction apart from one it has never seen and to bound the watch
+	Expiration string
+}
+
+type TransactionStatusUpdate struct {
+	TxId string
+	TransactionStatusResult
+}
+
+// WatchTransactions polls the status of each transaction every interval and
+// sends an update whenever it changes. The channel is closed once every
+// transaction has reached a final status or been dropped, or when ctx is
+// done. A transaction the node still reports as unknown unknownTxGrace after
+// its expiration is dropped without a final update.
+func (h *HiveRpcNode) WatchTransactions(ctx context.Context, txs []WatchedTransaction, interval time.Duration) (<-chan TransactionStatusUpdate, error) {
+	if interval <= 0 {
+		return nil, errors.New("watch interval must be positive")
+	}
+	expirations := make(map[string]time.Time, len(txs))
+	for _, tx := range txs {
+		exp, err := time.Parse(customTimeLayout, tx.Expiration)
+		if err != nil {
+			return nil, fmt.Errorf("transaction %s needs a valid expiration: %w", tx.TxId, err)
+		}
+		expirations[tx.TxId] = exp
+	}
+
+	updateChan := make(chan TransactionStatusUpdate)
+
+	go func() {
+		defer close(updateChan)
+
+		pending := make(map[string]WatchedTransaction, len(txs))
+		last := make(map[string]TransactionStatusResult, len(txs))
+		for _, tx := range txs {
+			pending[tx.TxId] = tx
+		}
+
+		for len(pending) > 0 {
+			for txId, tx := range pending {
+				status, err := h.FindTransaction(tx.TxId, tx.Expiration)
+				if err != nil {
+					log.Printf("Error fetching status of transaction %s: %v\n. Retrying...", txId, err)
+					status = &TransactionStatusResult{Status: TxStatusUnknown}
+				} else if prev, ok := last[txId]; !ok || prev != *status {
+					last[txId] = *status
+					select {
+					case updateChan <- TransactionStatusUpdate{TxId: txId, TransactionStatusResult: *status}:
+					case <-ctx.Done():
+						return
+					}
+				}
+
+				if status.Status.IsFinal() ||
+					(status.Status == TxStatusUnknown && time.Now().After(expirations[txId].Add(unknownTxGrace))) {
+					delete(pending, txId)
+				}
+			}
+
+			if len(pending) > 0 {
+				timer := time.NewTimer(interval)
+				select {
+				case <-timer.C:
+				case <-ctx.Done():
+					timer.Stop()
+					return
+				}
+			}
+		}
+	}()
+
+	return updateChan, nil
+}
@@ -0,0 +1,120 @@
+package hivego
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTransactionStatusIsFinal(t *testing.T) {
+	final := []TransactionStatus{TxStatusWithinIrreversibleBlock, TxStatusExpiredIrreversible, TxStatusTooOld}
+	for _, s := range final {
+		if !s.IsFinal() {
+			t.Error("Expected", s, "to be final")
+		}
+	}
+
+	pending := []TransactionStatus{TxStatusUnknown, TxStatusWithinMempool, TxStatusWithinReversibleBlock, TxStatusExpiredReversible}
+	for _, s := range pending {
+		if s.IsFinal() {
+			t.Error("Expected", s, "not to be final")
+		}
+	}
+}
+
+// handleStatuses answers find_transaction with statuses in turn, repeating
+// the last one
+func handleStatuses(node *testNode, statuses ...TransactionStatus) {
+	var mu sync.Mutex
+	node.handle("transaction_status_api.find_transaction", func(json.RawMessage) (interface{}, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		status := statuses[0]
+		if len(statuses) > 1 {
+			statuses = statuses[1:]
+		}
+		return TransactionStatusResult{Status: status, BlockNum: 10}, nil
+	})
+}
+
+// collect reads updates until the channel is closed, failing after timeout
+func collect(t *testing.T, updates <-chan TransactionStatusUpdate, timeout time.Duration) []TransactionStatus {
+	var got []TransactionStatus
+	deadline := time.After(timeout)
+	for {
+		select {
+		case update, ok := <-updates:
+			if !ok {
+				return got
+			}
+			got = append(got, update.Status)
+		case <-deadline:
+			t.Fatal("Expected the channel to be closed, got", got)
+		}
+	}
+}
+
+func futureExpiration() string {
+	return time.Now().UTC().Add(time.Minute).Format(customTimeLayout)
+}
+
+func TestWatchTransactions(t *testing.T) {
+	node, h := newTestNode(t)
+	handleStatuses(node, TxStatusWithinMempool, TxStatusWithinMempool, TxStatusWithinReversibleBlock, TxStatusWithinIrreversibleBlock)
+
+	updates, err := h.WatchTransactions(context.Background(), []WatchedTransaction{{"abc", futureExpiration()}}, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := collect(t, updates, 5*time.Second)
+	expected := []TransactionStatus{TxStatusWithinMempool, TxStatusWithinReversibleBlock, TxStatusWithinIrreversibleBlock}
+	if len(got) != len(expected) {
+		t.Fatal("Expected", expected, "got", got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Error("Expected", expected, "got", got)
+		}
+	}
+}
+
+func TestWatchTransactionsCancel(t *testing.T) {
+	node, h := newTestNode(t)
+	handleStatuses(node, TxStatusWithinMempool, TxStatusWithinReversibleBlock)
+
+	// a reader that stops reading cancels instead, which must end the watch
+	ctx, cancel := context.WithCancel(context.Background())
+	updates, err := h.WatchTransactions(ctx, []WatchedTransaction{{"abc", futureExpiration()}}, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	collect(t, updates, 5*time.Second)
+}
+
+func TestWatchTransactionsUnknownExpired(t *testing.T) {
+	node, h := newTestNode(t)
+	handleStatuses(node, TxStatusUnknown)
+
+	// long past its expiration, so the unknown transaction is dropped
+	updates, err := h.WatchTransactions(context.Background(), []WatchedTransaction{{"abc", "2016-08-08T12:24:17"}}, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := collect(t, updates, 5*time.Second); len(got) != 1 || got[0] != TxStatusUnknown {
+		t.Error("Expected a single unknown update, got", got)
+	}
+}
+
+func TestWatchTransactionsRequiresExpiration(t *testing.T) {
+	_, h := newTestNode(t)
+	if _, err := h.WatchTransactions(context.Background(), []WatchedTransaction{{TxId: "abc"}}, time.Second); err == nil {
+		t.Error("Expected an error for a transaction without an expiration")
+	}
+	if _, err := h.WatchTransactions(context.Background(), nil, 0); err == nil {
+		t.Error("Expected an error for a zero interval")
+	}
+}
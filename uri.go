@@ -0,0 +1,288 @@
+package hivego
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	uriScheme = "hive://"
+
+	PlaceholderSigner         = "__signer"
+	PlaceholderExpiration     = "__expiration"
+	PlaceholderRefBlockNum    = "__ref_block_num"
+	PlaceholderRefBlockPrefix = "__ref_block_prefix"
+)
+
+// SigningRequest is a decoded hive://sign/... URI as used by wallets for QR
+// codes and deep links. It holds either a whole transaction or operations;
+// both may contain placeholders that are filled in when it is resolved.
+type SigningRequest struct {
+	// Transaction is the raw condenser format transaction of a sign/tx request
+	Transaction json.RawMessage
+	// Operations are the raw condenser format operations of a sign/op or
+	// sign/ops request
+	Operations []json.RawMessage
+
+	// Signer is the account that should sign, if the request names one
+	Signer string
+	// Callback is a URL to visit after signing, which may contain the
+	// {{sig}}, {{id}}, {{block}} and {{txn}} placeholders
+	Callback string
+	// NoBroadcast asks for the transaction to be signed but not broadcast
+	NoBroadcast bool
+}
+
+// EncodeTransactionURI encodes a transaction as a hive://sign/tx/ URI
+func EncodeTransactionURI(tx *HiveTransaction, req SigningRequest) (string, error) {
+	tx.prepareJson()
+	txB, err := json.Marshal(tx)
+	if err != nil {
+		return "", err
+	}
+	req.Transaction = txB
+	req.Operations = nil
+	return req.Encode()
+}
+
+// EncodeOperationsURI encodes operations as a hive://sign/op/ URI for a single
+// operation or a hive://sign/ops/ URI for several. Operations may use
+// PlaceholderSigner in place of the signing account.
+func EncodeOperationsURI(ops []HiveOperation, req SigningRequest) (string, error) {
+	req.Transaction = nil
+	req.Operations = nil
+	for _, op := range ops {
+		opB, err := json.Marshal([2]interface{}{op.OpName(), op})
+		if err != nil {
+			return "", err
+		}
+		req.Operations = append(req.Operations, opB)
+	}
+	return req.Encode()
+}
+
+// Encode returns the request as a hive:// URI
+func (r *SigningRequest) Encode() (string, error) {
+	var action string
+	var payload []byte
+	var err error
+	switch {
+	case r.Transaction != nil:
+		action, payload = "tx", r.Transaction
+	case len(r.Operations) == 1:
+		action, payload = "op", r.Operations[0]
+	case len(r.Operations) > 1:
+		action = "ops"
+		payload, err = json.Marshal(r.Operations)
+		if err != nil {
+			return "", err
+		}
+	default:
+		return "", errors.New("signing request has no transaction or operations")
+	}
+
+	uri := uriScheme + "sign/" + action + "/" + encodeBase64Url(payload)
+
+	params := url.Values{}
+	if r.Signer != "" {
+		params.Set("s", r.Signer)
+	}
+	if r.Callback != "" {
+		params.Set("cb", encodeBase64Url([]byte(r.Callback)))
+	}
+	if r.NoBroadcast {
+		params.Set("nb", "")
+	}
+	if len(params) > 0 {
+		uri += "?" + params.Encode()
+	}
+	return uri, nil
+}
+
+// DecodeSigningRequest decodes a hive://sign/tx/, hive://sign/op/ or
+// hive://sign/ops/ URI
+func DecodeSigningRequest(uri string) (*SigningRequest, error) {
+	if !strings.HasPrefix(uri, uriScheme) {
+		return nil, errors.New("not a hive:// uri: " + uri)
+	}
+	rest := strings.TrimPrefix(uri, uriScheme)
+
+	var query string
+	if i := strings.IndexByte(rest, '?'); i >= 0 {
+		rest, query = rest[:i], rest[i+1:]
+	}
+
+	parts := strings.Split(rest, "/")
+	if len(parts) != 3 || parts[0] != "sign" {
+		return nil, errors.New("unsupported hive:// uri: " + uri)
+	}
+	payload, err := decodeBase64Url(parts[2])
+	if err != nil {
+		return nil, err
+	}
+
+	req := &SigningRequest{}
+	switch parts[1] {
+	case "tx":
+		req.Transaction = payload
+	case "op":
+		req.Operations = []json.RawMessage{payload}
+	case "ops":
+		err = json.Unmarshal(payload, &req.Operations)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New("unsupported signing action: " + parts[1])
+	}
+	if req.Transaction != nil && !json.Valid(req.Transaction) {
+		return nil, errors.New("invalid transaction json")
+	}
+	for _, op := range req.Operations {
+		if !json.Valid(op) {
+			return nil, errors.New("invalid operation json")
+		}
+	}
+
+	params, err := url.ParseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	req.Signer = params.Get("s")
+	if cb := params.Get("cb"); cb != "" {
+		cbB, err := decodeBase64Url(cb)
+		if err != nil {
+			return nil, err
+		}
+		req.Callback = string(cbB)
+	}
+	_, req.NoBroadcast = params["nb"]
+
+	return req, nil
+}
+
+// encodeBase64Url encodes like the reference hive-uri implementation, which
+// pads with '.' instead of '=' so the padding needs no escaping in URIs
+func encodeBase64Url(b []byte) string {
+	return strings.ReplaceAll(base64.URLEncoding.EncodeToString(b), "=", ".")
+}
+
+func decodeBase64Url(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, ".="))
+}
+
+// ResolveSigningRequest fills in the request's placeholders for signer and the
+// current chain state, returning a transaction ready to be signed
+func (h *HiveRpcNode) ResolveSigningRequest(req *SigningRequest, signer string) (*HiveTransaction, error) {
+	if req.Signer != "" && req.Signer != signer {
+		return nil, errors.New("signing request must be signed by " + req.Signer)
+	}
+
+	var tx interface{}
+	if req.Transaction != nil {
+		err := json.Unmarshal(req.Transaction, &tx)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		tx = map[string]interface{}{
+			"ref_block_num":    PlaceholderRefBlockNum,
+			"ref_block_prefix": PlaceholderRefBlockPrefix,
+			"expiration":       PlaceholderExpiration,
+			"operations":       req.Operations,
+			"extensions":       []interface{}{},
+		}
+		// round trip so the operations are walked as plain values
+		txB, err := json.Marshal(tx)
+		if err != nil {
+			return nil, err
+		}
+		err = json.Unmarshal(txB, &tx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	signingData, err := h.getSigningData()
+	if err != nil {
+		return nil, err
+	}
+	values := map[string]interface{}{
+		PlaceholderExpiration:     signingData.expiration,
+		PlaceholderRefBlockNum:    signingData.refBlockNum,
+		PlaceholderRefBlockPrefix: signingData.refBlockPrefix,
+	}
+
+	txB, err := json.Marshal(resolvePlaceholders(tx, signer, values))
+	if err != nil {
+		return nil, err
+	}
+	return h.network().ParseTransactionJson(txB)
+}
+
+// resolvePlaceholders replaces values that are exactly a placeholder, and
+// PlaceholderSigner anywhere within a string
+func resolvePlaceholders(v interface{}, signer string, values map[string]interface{}) interface{} {
+	switch val := v.(type) {
+	case string:
+		if resolved, ok := values[val]; ok {
+			return resolved
+		}
+		return strings.ReplaceAll(val, PlaceholderSigner, signer)
+	case []interface{}:
+		for i := range val {
+			val[i] = resolvePlaceholders(val[i], signer, values)
+		}
+	case map[string]interface{}:
+		for k := range val {
+			val[k] = resolvePlaceholders(val[k], signer, values)
+		}
+	}
+	return v
+}
+
+// SignSigningRequest resolves the request for account, signs it with signer
+// and, unless the request asks otherwise, broadcasts it
+func (h *HiveRpcNode) SignSigningRequest(req *SigningRequest, account string, signer Signer) (*HiveTransaction, string, error) {
+	tx, err := h.ResolveSigningRequest(req, account)
+	if err != nil {
+		return nil, "", err
+	}
+
+	err = tx.Sign(signer)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if req.NoBroadcast {
+		txId, err := tx.TxId()
+		return tx, txId, err
+	}
+
+	txId, err := h.BroadcastTransaction(tx)
+	if err != nil {
+		return nil, "", err
+	}
+	return tx, txId, nil
+}
+
+// CallbackURL fills in the callback's placeholders for a signed transaction.
+// blockNum and trxNum may be zero when they are not known.
+func (r *SigningRequest) CallbackURL(tx *HiveTransaction, txId string, blockNum int, trxNum int) string {
+	var sig string
+	if len(tx.Signatures) > 0 {
+		sig = tx.Signatures[0]
+	}
+
+	replacer := strings.NewReplacer(
+		"{{sig}}", url.QueryEscape(sig),
+		"{{id}}", url.QueryEscape(txId),
+		"{{block}}", strconv.Itoa(blockNum),
+		"{{txn}}", strconv.Itoa(trxNum),
+	)
+	return replacer.Replace(r.Callback)
+}
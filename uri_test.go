@@ -0,0 +1,70 @@
+package hivego
+
+import "testing"
+
+func TestDecodeSigningRequest(t *testing.T) {
+	req, err := DecodeSigningRequest("hive://sign/op/WyJ2b3RlIix7InZvdGVyIjoiZm9vIiwiYXV0aG9yIjoiYmFyIiwicGVybWxpbmsiOiJiYXoiLCJ3ZWlnaHQiOjEwMDAwfV0.?s=foo&nb")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `["vote",{"voter":"foo","author":"bar","permlink":"baz","weight":10000}]`
+	if len(req.Operations) != 1 || string(req.Operations[0]) != expected {
+		t.Error("Expected", expected, "got", req.Operations)
+	}
+	if req.Signer != "foo" || !req.NoBroadcast {
+		t.Error("Expected signer foo and no broadcast, got", req.Signer, req.NoBroadcast)
+	}
+
+	if _, err = DecodeSigningRequest("hive://sign/unknown/e30"); err == nil {
+		t.Error("Expected error for unsupported action")
+	}
+}
+
+func TestEncodeOperationsURIRoundTrip(t *testing.T) {
+	vote := voteOperation{PlaceholderSigner, "xeroc", "piston", 10000, "vote"}
+	uri, err := EncodeOperationsURI([]HiveOperation{vote, getTestCustomJsonOp()}, SigningRequest{Callback: "https://example.com/done?id={{id}}"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, err := DecodeSigningRequest(uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(req.Operations) != 2 || req.Callback != "https://example.com/done?id={{id}}" {
+		t.Fatal("Expected 2 operations and the callback, got", req)
+	}
+
+	node, h := newTestNode(t)
+	signer, _ := NewKeySigner("5JuMt237G3m3BaT7zH4YdoycUtbw4AEPy6DLdCrKAnFGAtXyQ1W")
+	req.NoBroadcast = true
+	tx, txId, err := h.SignSigningRequest(req, "xeroc", signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(node.broadcastOps()) != 0 {
+		t.Error("Expected nothing to be broadcast")
+	}
+
+	got, _ := tx.Operations[0].SerializeOp()
+	expected, _ := getTestVoteOp().SerializeOp()
+	if string(got) != string(expected) {
+		t.Error("Expected the signer placeholder to be resolved, got", tx.Operations[0])
+	}
+	if tx.RefBlockNum != 7229 || tx.Expiration != "2016-08-08T12:24:17" || len(tx.Signatures) != 1 {
+		t.Error("Expected resolved TaPoS, expiration and a signature, got", tx)
+	}
+
+	if cb := req.CallbackURL(tx, txId, 0, 0); cb != "https://example.com/done?id="+txId {
+		t.Error("Expected callback with the txid, got", cb)
+	}
+}
+
+func TestResolveSigningRequestSignerMismatch(t *testing.T) {
+	_, h := newTestNode(t)
+	req := &SigningRequest{Operations: nil, Signer: "foo"}
+	if _, err := h.ResolveSigningRequest(req, "bar"); err == nil {
+		t.Error("Expected error when resolving for a different signer")
+	}
+}
@@ -0,0 +1,311 @@
+package hivego
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// limits enforced by hived's operation and transaction validation
+const (
+	minAccountNameLength  = 3
+	maxAccountNameLength  = 16
+	maxPermlinkLength     = 256
+	maxVoteWeight         = 10000
+	maxCustomJsonIdLength = 32
+	maxCustomJsonLength   = 8192
+	maxMemoSize           = 2048
+)
+
+// ValidationError reports an operation or transaction that hived would reject
+type ValidationError struct {
+	// Op is the operation name, or empty for the transaction itself
+	Op     string
+	Field  string
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Op == "" {
+		return fmt.Sprintf("invalid transaction: %s: %s", e.Field, e.Reason)
+	}
+	return fmt.Sprintf("invalid %s operation: %s: %s", e.Op, e.Field, e.Reason)
+}
+
+func validationErr(op string, field string, reason string) *ValidationError {
+	return &ValidationError{Op: op, Field: field, Reason: reason}
+}
+
+// Validate checks the transaction against hived's rules, including that it
+// expires within the allowed window of the local clock
+func (t *HiveTransaction) Validate() error {
+	return t.validateAt(time.Now())
+}
+
+// validateAt validates the transaction with its expiration checked against
+// the given chain time
+func (t *HiveTransaction) validateAt(now time.Time) error {
+	if len(t.Operations) == 0 {
+		return validationErr("", "operations", "transaction has no operations")
+	}
+
+	seen := make(map[string]bool, len(t.Operations))
+	for _, op := range t.Operations {
+		err := op.Validate()
+		if err != nil {
+			return err
+		}
+		if nop, ok := op.(networkOperation); ok && nop.network().orMainnet().ChainId != t.network.orMainnet().ChainId {
+			return validationErr(op.OpName(), "", "operation was built for a different network")
+		}
+
+		opB, err := op.SerializeOp()
+		if err != nil {
+			return err
+		}
+		if seen[string(opB)] {
+			return validationErr("", "operations", "duplicate "+op.OpName()+" operation")
+		}
+		seen[string(opB)] = true
+	}
+
+	exp, err := time.Parse(customTimeLayout, t.Expiration)
+	if err != nil {
+		return validationErr("", "expiration", err.Error())
+	}
+	if !exp.After(now) {
+		return validationErr("", "expiration", "transaction has expired")
+	}
+	if exp.After(now.Add(maxTxExpiration)) {
+		return validationErr("", "expiration", "expiration is more than "+maxTxExpiration.String()+" in the future")
+	}
+
+	serialized, err := serializeTx(*t)
+	if err != nil {
+		return err
+	}
+	sigCount := len(t.Signatures)
+	if sigCount == 0 {
+		sigCount = 1
+	}
+	if size := len(serialized) + 1 + 65*sigCount; size > maxTransactionSize {
+		return validationErr("", "size", fmt.Sprintf("%d bytes exceeds the maximum of %d", size, maxTransactionSize))
+	}
+	return nil
+}
+
+func (o voteOperation) Validate() error {
+	if err := validateAccountName(o.opText, "voter", o.Voter); err != nil {
+		return err
+	}
+	if err := validateAccountName(o.opText, "author", o.Author); err != nil {
+		return err
+	}
+	if err := validatePermlink(o.opText, "permlink", o.Permlink); err != nil {
+		return err
+	}
+	return validateVoteWeight(o.opText, int(o.Weight))
+}
+
+func (o customJsonOperation) Validate() error {
+	if len(o.RequiredAuths)+len(o.RequiredPostingAuths) == 0 {
+		return validationErr(o.opText, "required_auths", "at least one account must authorize the operation")
+	}
+	for _, account := range o.RequiredAuths {
+		if err := validateAccountName(o.opText, "required_auths", account); err != nil {
+			return err
+		}
+	}
+	for _, account := range o.RequiredPostingAuths {
+		if err := validateAccountName(o.opText, "required_posting_auths", account); err != nil {
+			return err
+		}
+	}
+	if len(o.Id) > maxCustomJsonIdLength {
+		return validationErr(o.opText, "id", "must be at most "+strconv.Itoa(maxCustomJsonIdLength)+" characters")
+	}
+	if len(o.Json) > maxCustomJsonLength {
+		return validationErr(o.opText, "json", "must be at most "+strconv.Itoa(maxCustomJsonLength)+" bytes")
+	}
+	if !json.Valid([]byte(o.Json)) {
+		return validationErr(o.opText, "json", "not valid JSON")
+	}
+	return nil
+}
+
+func (o claimRewardOperation) Validate() error {
+	if err := validateAccountName(o.opText, "account", o.Account); err != nil {
+		return err
+	}
+
+	total := int64(0)
+	symbols := o.net.orMainnet().Symbols
+	for _, reward := range []struct{ field, asset, symbol string }{
+		{"reward_hive", o.RewardHIVE, symbols.Hive},
+		{"reward_hbd", o.RewardHBD, symbols.Hbd},
+		{"reward_vests", o.RewardVests, symbols.Vests},
+	} {
+		amount, err := validateAsset(o.net, o.opText, reward.field, reward.asset, reward.symbol)
+		if err != nil {
+			return err
+		}
+		if amount < 0 {
+			return validationErr(o.opText, reward.field, "cannot be negative")
+		}
+		total += amount
+	}
+	if total == 0 {
+		return validationErr(o.opText, "reward", "must claim a non-zero amount")
+	}
+	return nil
+}
+
+func (o transferOperation) Validate() error {
+	if err := validateAccountName(o.opText, "from", o.From); err != nil {
+		return err
+	}
+	if err := validateAccountName(o.opText, "to", o.To); err != nil {
+		return err
+	}
+
+	symbols := o.net.orMainnet().Symbols
+	amount, err := validateAsset(o.net, o.opText, "amount", o.Amount, symbols.Hive, symbols.Hbd)
+	if err != nil {
+		return err
+	}
+	if amount <= 0 {
+		return validationErr(o.opText, "amount", "must be positive")
+	}
+	if isPlaintextPrivateMemo(o.Memo) {
+		return validationErr(o.opText, "memo", "starts with # but is not encrypted")
+	}
+
+	if len(o.Memo) >= maxMemoSize {
+		return validationErr(o.opText, "memo", "must be shorter than "+strconv.Itoa(maxMemoSize)+" bytes")
+	}
+	if !utf8.ValidString(o.Memo) {
+		return validationErr(o.opText, "memo", "not valid UTF-8")
+	}
+	return nil
+}
+
+func (o accountUpdateOperation) Validate() error {
+	if err := validateAccountName(o.opText, "account", o.Account); err != nil {
+		return err
+	}
+
+	for _, auth := range []struct {
+		field string
+		auth  *Auths
+	}{{"owner", o.Owner}, {"active", o.Active}, {"posting", o.Posting}} {
+		if auth.auth == nil {
+			continue
+		}
+		var buf bytes.Buffer
+		if err := serializeAuthority(*auth.auth, o.net, &buf); err != nil {
+			return validationErr(o.opText, auth.field, err.Error())
+		}
+		for _, accountAuth := range auth.auth.AccountAuths {
+			account, _ := accountAuth[0].(string)
+			if err := validateAccountName(o.opText, auth.field, account); err != nil {
+				return err
+			}
+		}
+	}
+
+	if _, err := o.MemoKey.bytesFor(o.net); err != nil {
+		return validationErr(o.opText, "memo_key", err.Error())
+	}
+	if o.JsonMetadata != "" && !json.Valid([]byte(o.JsonMetadata)) {
+		return validationErr(o.opText, "json_metadata", "not valid JSON")
+	}
+	return nil
+}
+
+// validateAccountName mirrors hived's is_valid_account_name: 3 to 16
+// characters made of dot separated segments, each at least 3 characters long,
+// starting with a letter, ending with a letter or digit and otherwise
+// containing only letters, digits and dashes
+func validateAccountName(op string, field string, name string) error {
+	if len(name) < minAccountNameLength || len(name) > maxAccountNameLength {
+		return validationErr(op, field, fmt.Sprintf("account name %q must be %d to %d characters", name, minAccountNameLength, maxAccountNameLength))
+	}
+
+	for _, segment := range strings.Split(name, ".") {
+		if len(segment) < minAccountNameLength {
+			return validationErr(op, field, fmt.Sprintf("account name %q has a segment shorter than %d characters", name, minAccountNameLength))
+		}
+		if !isLower(segment[0]) {
+			return validationErr(op, field, fmt.Sprintf("account name %q has a segment not starting with a letter", name))
+		}
+		if last := segment[len(segment)-1]; !isLower(last) && !isDigit(last) {
+			return validationErr(op, field, fmt.Sprintf("account name %q has a segment not ending with a letter or digit", name))
+		}
+		for i := 1; i < len(segment)-1; i++ {
+			if c := segment[i]; !isLower(c) && !isDigit(c) && c != '-' {
+				return validationErr(op, field, fmt.Sprintf("account name %q contains invalid character %q", name, c))
+			}
+		}
+	}
+	return nil
+}
+
+func validatePermlink(op string, field string, permlink string) error {
+	if len(permlink) >= maxPermlinkLength {
+		return validationErr(op, field, fmt.Sprintf("must be shorter than %d bytes", maxPermlinkLength))
+	}
+	if !utf8.ValidString(permlink) {
+		return validationErr(op, field, "must be valid UTF-8")
+	}
+	return nil
+}
+
+func validateVoteWeight(op string, weight int) error {
+	if weight < -maxVoteWeight || weight > maxVoteWeight {
+		return validationErr(op, "weight", fmt.Sprintf("must be between %d and %d", -maxVoteWeight, maxVoteWeight))
+	}
+	return nil
+}
+
+// validateAsset checks an asset string has the form "1.000 HIVE" with the
+// precision of its symbol on the network and one of the allowed symbols,
+// returning the amount in the asset's smallest unit
+func validateAsset(net *NetworkConfig, op string, field string, asset string, symbols ...string) (int64, error) {
+	parts := strings.Split(asset, " ")
+	if len(parts) != 2 {
+		return 0, validationErr(op, field, fmt.Sprintf("invalid asset %q", asset))
+	}
+	amountStr, symbol := parts[0], parts[1]
+
+	allowed := false
+	for _, s := range symbols {
+		allowed = allowed || s == symbol
+	}
+	if !allowed {
+		return 0, validationErr(op, field, fmt.Sprintf("symbol must be one of %s, got %q", strings.Join(symbols, ", "), symbol))
+	}
+
+	_, precision, _ := net.serializedSymbol(symbol)
+	dot := strings.IndexByte(amountStr, '.')
+	if dot < 0 || len(amountStr)-dot-1 != precision {
+		return 0, validationErr(op, field, fmt.Sprintf("%s amounts must have %d decimal places, got %q", symbol, precision, amountStr))
+	}
+
+	amount, err := strconv.ParseInt(amountStr[:dot]+amountStr[dot+1:], 10, 64)
+	if err != nil {
+		return 0, validationErr(op, field, fmt.Sprintf("invalid amount %q", amountStr))
+	}
+	return amount, nil
+}
+
+func isLower(c byte) bool {
+	return c >= 'a' && c <= 'z'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
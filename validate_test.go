@@ -0,0 +1,132 @@
+package hivego
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateAccountName(t *testing.T) {
+	valid := []string{"xeroc", "abc", "a-b-c", "hive.blog", "x12", "abcdefghijklmnop"}
+	for _, name := range valid {
+		if err := validateAccountName("vote", "voter", name); err != nil {
+			t.Error("Expected", name, "to be valid, got", err)
+		}
+	}
+
+	invalid := []string{"", "ab", "abcdefghijklmnopq", "Xeroc", "1abc", "abc-", "ab.cde", "abc..def", "ab_c"}
+	for _, name := range invalid {
+		if err := validateAccountName("vote", "voter", name); err == nil {
+			t.Error("Expected", name, "to be invalid")
+		}
+	}
+}
+
+func TestValidateVoteOperation(t *testing.T) {
+	if err := getTestVoteOp().Validate(); err != nil {
+		t.Error("Expected valid vote, got", err)
+	}
+
+	vote := getTestVoteOp().(voteOperation)
+	vote.Weight = -10001
+	var vErr *ValidationError
+	if err := vote.Validate(); !errors.As(err, &vErr) || vErr.Field != "weight" {
+		t.Error("Expected weight validation error, got", err)
+	}
+
+	// hived only checks a permlink's length and encoding
+	vote = getTestVoteOp().(voteOperation)
+	vote.Permlink = "Legacy_Permlink.2016"
+	if err := vote.Validate(); err != nil {
+		t.Error("Expected valid vote, got", err)
+	}
+
+	vote.Permlink = "bad-\xff"
+	if err := vote.Validate(); !errors.As(err, &vErr) || vErr.Field != "permlink" {
+		t.Error("Expected permlink validation error, got", err)
+	}
+
+	vote.Permlink = strings.Repeat("a", 256)
+	if err := vote.Validate(); !errors.As(err, &vErr) || vErr.Field != "permlink" {
+		t.Error("Expected permlink validation error, got", err)
+	}
+}
+
+func TestValidateCustomJsonOperation(t *testing.T) {
+	if err := getTestCustomJsonOp().Validate(); err != nil {
+		t.Error("Expected valid custom_json, got", err)
+	}
+
+	cases := map[string]customJsonOperation{
+		"id":             {[]string{}, []string{"xeroc"}, strings.Repeat("a", 33), "{}", "custom_json"},
+		"json":           {[]string{}, []string{"xeroc"}, "test-id", "{", "custom_json"},
+		"required_auths": {[]string{}, []string{}, "test-id", "{}", "custom_json"},
+	}
+	for field, op := range cases {
+		var vErr *ValidationError
+		if err := op.Validate(); !errors.As(err, &vErr) || vErr.Field != field {
+			t.Error("Expected", field, "validation error, got", err)
+		}
+	}
+}
+
+func TestValidateTransferOperation(t *testing.T) {
+	valid := transferOperation{"xeroc", "piston", "1.000 HIVE", "memo", "transfer", nil}
+	if err := valid.Validate(); err != nil {
+		t.Error("Expected valid transfer, got", err)
+	}
+
+	for _, amount := range []string{"1.000 VESTS", "1.0 HIVE", "0.000 HBD", "-1.000 HIVE", "1.000"} {
+		op := valid
+		op.Amount = amount
+		if err := op.Validate(); err == nil {
+			t.Error("Expected amount", amount, "to be invalid")
+		}
+	}
+
+	op := valid
+	op.Memo = strings.Repeat("a", maxMemoSize)
+	if err := op.Validate(); err == nil {
+		t.Error("Expected oversized memo to be invalid")
+	}
+}
+
+func TestValidateClaimRewardOperation(t *testing.T) {
+	op := claimRewardOperation{"xeroc", "0.000 HBD", "0.000 HIVE", "1.000000 VESTS", "claim_reward_balance", nil}
+	if err := op.Validate(); err != nil {
+		t.Error("Expected valid claim, got", err)
+	}
+
+	op.RewardVests = "0.000000 VESTS"
+	if err := op.Validate(); err == nil {
+		t.Error("Expected claim of nothing to be invalid")
+	}
+}
+
+func TestValidateHiveTransaction(t *testing.T) {
+	now, _ := time.Parse(customTimeLayout, "2016-08-08T12:23:47")
+
+	tx := getTestVoteTx()
+	if err := tx.validateAt(now); err != nil {
+		t.Error("Expected valid transaction, got", err)
+	}
+
+	if err := tx.validateAt(now.Add(time.Minute)); err == nil {
+		t.Error("Expected expired transaction to be invalid")
+	}
+
+	if err := tx.validateAt(now.Add(-2 * time.Hour)); err == nil {
+		t.Error("Expected expiration beyond the maximum to be invalid")
+	}
+
+	tx = getTestTx([]HiveOperation{getTestVoteOp(), getTestVoteOp()})
+	if err := tx.validateAt(now); err == nil {
+		t.Error("Expected duplicate operations to be invalid")
+	}
+
+	tx = getTestTx(nil)
+	if err := tx.validateAt(now); err == nil {
+		t.Error("Expected empty transaction to be invalid")
+	}
+}
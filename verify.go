@@ -0,0 +1,105 @@
+package hivego
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v2"
+)
+
+// Digest returns the chain specific digest covered by the transaction's signatures
+func (t *HiveTransaction) Digest() ([]byte, error) {
+	message, err := serializeTx(*t)
+	if err != nil {
+		return nil, err
+	}
+	chainId, err := t.network.chainId()
+	if err != nil {
+		return nil, err
+	}
+	return hashTxForSig(chainId, message), nil
+}
+
+// SigningKeys recovers the public key behind each of the transaction's signatures
+func (t *HiveTransaction) SigningKeys() ([]*secp256k1.PublicKey, error) {
+	digest, err := t.Digest()
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []*secp256k1.PublicKey
+	for _, sigHex := range t.Signatures {
+		sig, err := hex.DecodeString(sigHex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid signature %s: %w", sigHex, err)
+		}
+		pubKey, err := RecoverPublicKey(digest, sig)
+		if err != nil {
+			return nil, fmt.Errorf("invalid signature %s: %w", sigHex, err)
+		}
+		keys = append(keys, pubKey)
+	}
+	return keys, nil
+}
+
+// VerifySignatures checks that the transaction carries a valid signature from
+// each of the expected public keys
+func (t *HiveTransaction) VerifySignatures(expected []string) error {
+	signers, err := t.signingKeyStrings()
+	if err != nil {
+		return err
+	}
+
+	for _, key := range expected {
+		if !signers[key] {
+			return fmt.Errorf("missing signature from %s", key)
+		}
+	}
+	return nil
+}
+
+// VerifyAuthority checks that the transaction's signatures satisfy the given
+// authority (owner, active or posting) of account as it is currently on chain
+func (h *HiveRpcNode) VerifyAuthority(tx *HiveTransaction, account string, role string) error {
+	signers, err := tx.signingKeyStrings()
+	if err != nil {
+		return err
+	}
+	return h.checkAuthority(account, role, signers)
+}
+
+// checkAuthority checks that the keys in signers satisfy account's role
+// authority as it is currently on chain, following its account auths
+func (h *HiveRpcNode) checkAuthority(account string, role string, signers map[string]bool) error {
+	lookup := h.AccountAuthorityLookup()
+	auth, err := lookup(account, role)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(signers))
+	for key := range signers {
+		keys = append(keys, key)
+	}
+	_, ok, err := auth.CheckKeys(role, keys, lookup)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("signatures do not satisfy %s's %s authority", account, role)
+	}
+	return nil
+}
+
+func (t *HiveTransaction) signingKeyStrings() (map[string]bool, error) {
+	keys, err := t.SigningKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	signers := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		signers[*t.network.PublicKeyString(key)] = true
+	}
+	return signers, nil
+}
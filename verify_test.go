@@ -0,0 +1,46 @@
+package hivego
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestSigningKeysHiveTransaction(t *testing.T) {
+	wif := "5JuMt237G3m3BaT7zH4YdoycUtbw4AEPy6DLdCrKAnFGAtXyQ1W"
+	keyPair, _ := KeyPairFromWif(wif)
+	expected := *keyPair.GetPublicKeyString()
+
+	tx := getTestVoteTx()
+	digest, err := tx.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, _ := SignDigest(digest, &wif)
+	tx.Signatures = []string{hex.EncodeToString(sig)}
+
+	keys, err := tx.SigningKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || *GetPublicKeyString(keys[0]) != expected {
+		t.Error("Expected", expected, "got", keys)
+	}
+
+	if err := tx.VerifySignatures([]string{expected}); err != nil {
+		t.Error("Expected signature to verify, got", err)
+	}
+
+	tx.Operations = []HiveOperation{getTestCustomJsonOp()}
+	if err := tx.VerifySignatures([]string{expected}); err == nil {
+		t.Error("Expected verification of a tampered transaction to fail")
+	}
+}
+
+func TestKeyWeightAuthority(t *testing.T) {
+	auth := keyAuthority(2, "1", "2")
+
+	got := auth.keyWeight(map[string]bool{testKey("1").String(): true, testKey("3").String(): true})
+	if got != 1 {
+		t.Error("Expected", 1, "got", got)
+	}
+}